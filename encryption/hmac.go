@@ -0,0 +1,25 @@
+package encryption
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// SignHMAC returns the hex encoded HMAC-SHA256 of message using key
+func SignHMAC(message string, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(message))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyHMAC checks a hex encoded HMAC-SHA256 signature in constant time
+func VerifyHMAC(message, signature string, key []byte) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(message))
+	return hmac.Equal(mac.Sum(nil), expected)
+}
@@ -0,0 +1,33 @@
+package encryption
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var hmacKey = []byte("hmac-secret")
+
+func TestSignHMAC_success(t *testing.T) {
+	signature := SignHMAC("test-payload", hmacKey)
+
+	assert.Len(t, signature, 64)
+	assert.Equal(t, signature, SignHMAC("test-payload", hmacKey))
+	assert.NotEqual(t, signature, SignHMAC("other-payload", hmacKey))
+}
+
+func TestVerifyHMAC_success(t *testing.T) {
+	signature := SignHMAC("test-payload", hmacKey)
+
+	assert.True(t, VerifyHMAC("test-payload", signature, hmacKey))
+}
+
+func TestVerifyHMAC_wrongKey_failure(t *testing.T) {
+	signature := SignHMAC("test-payload", hmacKey)
+
+	assert.False(t, VerifyHMAC("test-payload", signature, []byte("garbage")))
+}
+
+func TestVerifyHMAC_notHex_failure(t *testing.T) {
+	assert.False(t, VerifyHMAC("test-payload", "garbage", hmacKey))
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Admiral-Piett/go-tools/gin/interfaces"
+	"github.com/Admiral-Piett/go-tools/gin/models"
+	"github.com/Admiral-Piett/go-tools/gin/services"
+	"github.com/Admiral-Piett/go-tools/gin/utils"
+	"github.com/Admiral-Piett/go-tools/logging"
+	password "github.com/Admiral-Piett/go-tools/password"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuthHandler provides the standard login, refresh and logout endpoints.  The session service
+// is optional - pass nil to skip session tracking.
+//
+//	router.POST("/login", h.PostLogin)
+//	router.POST("/refresh", h.PostRefresh)
+//	router.POST("/logout", authMiddleware.RequireAuth(), h.PostLogout)
+type AuthHandler struct {
+	users          authUsers
+	tokenService   interfaces.TokenServiceInterface
+	sessionService interfaces.SessionServiceInterface
+}
+
+func NewAuthHandler(
+	users interfaces.UserLookupInterface,
+	tokenService interfaces.TokenServiceInterface,
+	sessionService interfaces.SessionServiceInterface,
+) *AuthHandler {
+	return &AuthHandler{
+		users:          &intUsers{lookup: users, tokenService: tokenService},
+		tokenService:   tokenService,
+		sessionService: sessionService,
+	}
+}
+
+// NewStringUserAuthHandler is NewAuthHandler for user models keyed by strings or UUIDs
+func NewStringUserAuthHandler(
+	users interfaces.StringUserLookupInterface,
+	tokenService interfaces.StringUserTokenServiceInterface,
+	sessionService interfaces.SessionServiceInterface,
+) *AuthHandler {
+	return &AuthHandler{
+		users:          &stringUsers{lookup: users, tokenService: tokenService},
+		tokenService:   tokenService,
+		sessionService: sessionService,
+	}
+}
+
+// PostLogin exchanges a username and password for a token pair
+func (h *AuthHandler) PostLogin(c *gin.Context) {
+	request := models.PostLoginRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponses.BadRequest)
+		return
+	}
+	if request.Username == "" || request.Password == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponses.ValidationError)
+		return
+	}
+
+	user, hash, salt, err := h.users.findByUsername(request.Username)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).
+			WithError(err).
+			Error("Find User By Username Failure")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponses.GeneralError)
+		return
+	}
+	if user == nil {
+		password.ValidatePassword(request.Password, password.TimingHash, password.TimingSalt)
+		logging.FromContext(c.Request.Context()).Warning("Login Failure - Unknown User")
+		c.JSON(http.StatusUnauthorized, models.ErrorResponses.UnauthorizedError)
+		return
+	}
+	if !password.ValidatePassword(request.Password, hash, salt) {
+		logging.FromContext(c.Request.Context()).Warning("Login Failure - Invalid Password")
+		c.JSON(http.StatusUnauthorized, models.ErrorResponses.UnauthorizedError)
+		return
+	}
+
+	deviceToken := request.DeviceToken
+	if deviceToken == "" {
+		deviceToken = user.deviceToken
+	}
+	response, err := user.generateTokens(deviceToken)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).
+			WithError(err).
+			Error("Generate Token Response Failure")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponses.GeneralError)
+		return
+	}
+
+	if h.sessionService != nil {
+		_, err := h.sessionService.CreateSession(
+			user.id,
+			deviceToken,
+			c.Request.UserAgent(),
+			utils.GetClientIP(c),
+		)
+		if err != nil {
+			logging.FromContext(c.Request.Context()).WithError(err).Error("Create Session Failure")
+			c.JSON(http.StatusInternalServerError, models.ErrorResponses.GeneralError)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// PostRefresh exchanges a refresh token for a new token pair
+func (h *AuthHandler) PostRefresh(c *gin.Context) {
+	request := models.PostRefreshRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponses.BadRequest)
+		return
+	}
+	if request.RefreshToken == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponses.ValidationError)
+		return
+	}
+
+	claims, err := utils.ValidateRefreshTokenClaims(h.tokenService, request.RefreshToken)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).
+			WithError(err).
+			Warning("Validate Refresh Token Failure")
+		c.JSON(http.StatusUnauthorized, models.ErrorResponses.UnauthorizedError)
+		return
+	}
+
+	userId, err := h.users.decryptUserId(claims.Id)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).WithError(err).Warning("Decrypt User Id Failure")
+		c.JSON(http.StatusUnauthorized, models.ErrorResponses.UnauthorizedError)
+		return
+	}
+
+	user, err := h.users.findById(userId)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Find User By Id Failure")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponses.GeneralError)
+		return
+	}
+	if user == nil {
+		logging.FromContext(c.Request.Context()).Warning("Refresh Failure - Unknown User")
+		c.JSON(http.StatusUnauthorized, models.ErrorResponses.UnauthorizedError)
+		return
+	}
+
+	// The session is the one the refresh token was issued for, not whichever device the user
+	// model holds now.  Token services that don't report the device leave only the latter.
+	deviceToken := claims.DeviceToken
+	if _, ok := h.tokenService.(interfaces.RefreshClaimsValidator); !ok {
+		deviceToken = user.deviceToken
+	}
+	if h.sessionService != nil {
+		_, err := h.sessionService.RefreshSession(
+			userId,
+			deviceToken,
+			c.Request.UserAgent(),
+			utils.GetClientIP(c),
+		)
+		if errors.Is(err, services.ErrSessionNotFound) ||
+			errors.Is(err, services.ErrSessionRevoked) {
+			logging.FromContext(c.Request.Context()).
+				WithError(err).
+				Warning("Refresh Failure - Session Inactive")
+			c.JSON(http.StatusUnauthorized, models.ErrorResponses.UnauthorizedError)
+			return
+		}
+		if err != nil {
+			logging.FromContext(c.Request.Context()).WithError(err).Error("Refresh Session Failure")
+			c.JSON(http.StatusInternalServerError, models.ErrorResponses.GeneralError)
+			return
+		}
+	}
+
+	response, err := user.generateTokens(deviceToken)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).
+			WithError(err).
+			Error("Generate Token Response Failure")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponses.GeneralError)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// PostLogout revokes the caller's current session.  It must sit behind
+// `AuthMiddleware.RequireAuth`.  Without a session service there's nothing to revoke server
+// side, the client just discards its tokens.
+func (h *AuthHandler) PostLogout(c *gin.Context) {
+	userId, ok := utils.GetUserIdString(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponses.UnauthorizedError)
+		return
+	}
+
+	if h.sessionService != nil {
+		deviceToken, _ := utils.GetDeviceToken(c)
+		err := h.sessionService.RevokeDeviceSession(userId, deviceToken)
+		if err != nil && !errors.Is(err, services.ErrSessionNotFound) {
+			logging.FromContext(c.Request.Context()).WithError(err).Error("Revoke Session Failure")
+			c.JSON(http.StatusInternalServerError, models.ErrorResponses.GeneralError)
+			return
+		}
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,509 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Admiral-Piett/go-tools/gin/interfaces"
+	"github.com/Admiral-Piett/go-tools/gin/mocks"
+	"github.com/Admiral-Piett/go-tools/gin/models"
+	"github.com/Admiral-Piett/go-tools/gin/services"
+	"github.com/Admiral-Piett/go-tools/gin/test_helpers"
+	"github.com/Admiral-Piett/go-tools/gorm/database"
+	"github.com/Admiral-Piett/go-tools/settings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testPassword     = "password"
+	testPasswordHash = "$2a$10$yBIDtRKQQM4uP0MlYLjHlO4wNvYlJBZ872drjRAzkLzTSobGZZZHK"
+	testPasswordSalt = "dNSczLZ/bqPL5GHpyx+Y1w=="
+)
+
+func newTestUserLookup() *mocks.MockUserLookup {
+	users := &mocks.MockUserLookup{}
+	users.MockFindUserByUsername = func(username string) (interfaces.UserModelInterface, string, string, error) {
+		return &mocks.UserMock{}, testPasswordHash, testPasswordSalt, nil
+	}
+	return users
+}
+
+func newTestTokenService() *mocks.MockTokenService {
+	tok := &mocks.MockTokenService{}
+	tok.MockGenerateTokenResponse = func(user interfaces.UserModelInterface) (*models.TokenResponse, error) {
+		return &models.TokenResponse{AccessToken: "access", RefreshToken: "refresh"}, nil
+	}
+	tok.MockDecryptUserID = func(encryptedUserID string) (int, error) {
+		return 1, nil
+	}
+	return tok
+}
+
+func TestAuthHandler_PostLogin_success(t *testing.T) {
+	users := newTestUserLookup()
+	tok := newTestTokenService()
+	sessions := &mocks.MockSessionService{}
+	h := NewAuthHandler(users, tok, sessions)
+
+	w := test_helpers.ServeRequest("POST", "/login", h.PostLogin, models.PostLoginRequest{
+		Username: "user",
+		Password: testPassword,
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response models.TokenResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Nil(t, err)
+	assert.Equal(t, "access", response.AccessToken)
+	assert.Equal(t, "refresh", response.RefreshToken)
+
+	assert.Equal(t, []interface{}{"user"}, users.FindUserByUsernameCalledWith)
+	assert.Equal(t, "1", sessions.CreateSessionCalledWith[0])
+	assert.Equal(t, "device-token", sessions.CreateSessionCalledWith[1])
+}
+
+func TestAuthHandler_PostLogin_twoDevices_success(t *testing.T) {
+	tok := newTestTokenService()
+	sessions := &mocks.MockSessionService{}
+	h := NewAuthHandler(newTestUserLookup(), tok, sessions)
+
+	for _, device := range []string{"phone", "laptop"} {
+		w := test_helpers.ServeRequest("POST", "/login", h.PostLogin, models.PostLoginRequest{
+			Username:    "user",
+			Password:    testPassword,
+			DeviceToken: device,
+		})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		// The requested device, not the one on the user model
+		assert.Equal(t, "1", sessions.CreateSessionCalledWith[0])
+		assert.Equal(t, device, sessions.CreateSessionCalledWith[1])
+		user := tok.GenerateTokenResponseCalledWith[0].(interfaces.UserModelInterface)
+		assert.Equal(t, device, user.GetDeviceToken())
+	}
+}
+
+func TestAuthHandler_PostLogin_withoutSessionService_success(t *testing.T) {
+	h := NewAuthHandler(newTestUserLookup(), newTestTokenService(), nil)
+
+	w := test_helpers.ServeRequest("POST", "/login", h.PostLogin, models.PostLoginRequest{
+		Username: "user",
+		Password: testPassword,
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAuthHandler_PostLogin_invalidBody_400(t *testing.T) {
+	h := NewAuthHandler(newTestUserLookup(), newTestTokenService(), nil)
+
+	w := test_helpers.ServeRequest("POST", "/login", h.PostLogin, "garbage")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response models.ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, models.ErrorResponses.BadRequest, response)
+}
+
+func TestAuthHandler_PostLogin_missingFields_400(t *testing.T) {
+	h := NewAuthHandler(newTestUserLookup(), newTestTokenService(), nil)
+
+	w := test_helpers.ServeRequest("POST", "/login", h.PostLogin, models.PostLoginRequest{
+		Username: "user",
+	})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response models.ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, models.ErrorResponses.ValidationError, response)
+}
+
+func TestAuthHandler_PostLogin_lookupError_500(t *testing.T) {
+	users := &mocks.MockUserLookup{}
+	users.MockFindUserByUsername = func(username string) (interfaces.UserModelInterface, string, string, error) {
+		return nil, "", "", errors.New("boom")
+	}
+	h := NewAuthHandler(users, newTestTokenService(), nil)
+
+	w := test_helpers.ServeRequest("POST", "/login", h.PostLogin, models.PostLoginRequest{
+		Username: "user",
+		Password: testPassword,
+	})
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestAuthHandler_PostLogin_unknownUser_401(t *testing.T) {
+	users := &mocks.MockUserLookup{}
+	users.MockFindUserByUsername = func(username string) (interfaces.UserModelInterface, string, string, error) {
+		return nil, "", "", nil
+	}
+	tok := newTestTokenService()
+	h := NewAuthHandler(users, tok, nil)
+
+	w := test_helpers.ServeRequest("POST", "/login", h.PostLogin, models.PostLoginRequest{
+		Username: "user",
+		Password: testPassword,
+	})
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Nil(t, tok.GenerateTokenResponseCalledWith)
+}
+
+func TestAuthHandler_PostLogin_wrongPassword_401(t *testing.T) {
+	tok := newTestTokenService()
+	sessions := &mocks.MockSessionService{}
+	h := NewAuthHandler(newTestUserLookup(), tok, sessions)
+
+	w := test_helpers.ServeRequest("POST", "/login", h.PostLogin, models.PostLoginRequest{
+		Username: "user",
+		Password: "garbage",
+	})
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	var response models.ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, models.ErrorResponses.UnauthorizedError, response)
+	assert.Nil(t, tok.GenerateTokenResponseCalledWith)
+	assert.Nil(t, sessions.CreateSessionCalledWith)
+}
+
+func TestAuthHandler_PostLogin_generateTokenError_500(t *testing.T) {
+	tok := newTestTokenService()
+	tok.MockGenerateTokenResponse = func(user interfaces.UserModelInterface) (*models.TokenResponse, error) {
+		return nil, errors.New("boom")
+	}
+	h := NewAuthHandler(newTestUserLookup(), tok, nil)
+
+	w := test_helpers.ServeRequest("POST", "/login", h.PostLogin, models.PostLoginRequest{
+		Username: "user",
+		Password: testPassword,
+	})
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestAuthHandler_PostLogin_createSessionError_500(t *testing.T) {
+	sessions := &mocks.MockSessionService{}
+	sessions.MockCreateSession = func(userId, deviceToken, userAgent, ipAddress string) (*models.Session, error) {
+		return nil, errors.New("boom")
+	}
+	h := NewAuthHandler(newTestUserLookup(), newTestTokenService(), sessions)
+
+	w := test_helpers.ServeRequest("POST", "/login", h.PostLogin, models.PostLoginRequest{
+		Username: "user",
+		Password: testPassword,
+	})
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestAuthHandler_PostRefresh_success(t *testing.T) {
+	users := newTestUserLookup()
+	tok := newTestTokenService()
+	tok.MockValidateRefreshTokenClaims = func(tokenString string) (*models.RefreshClaims, error) {
+		return &models.RefreshClaims{
+			DeviceToken:    "phone",
+			StandardClaims: jwt.StandardClaims{Id: "encrypted-user-id"},
+		}, nil
+	}
+	sessions := &mocks.MockSessionService{}
+	h := NewAuthHandler(users, tok, sessions)
+
+	w := test_helpers.ServeRequest("POST", "/refresh", h.PostRefresh, models.PostRefreshRequest{
+		RefreshToken: "refresh",
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response models.TokenResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, "access", response.AccessToken)
+
+	assert.Equal(t, []interface{}{"refresh"}, tok.ValidateRefreshTokenClaimsCalledWith)
+	assert.Equal(t, []interface{}{"encrypted-user-id"}, tok.DecryptUserIDCalledWith)
+	assert.Equal(t, []interface{}{1}, users.FindUserByIdCalledWith)
+	// The refresh token's device, not the one on the user model
+	assert.Equal(t, "1", sessions.RefreshSessionCalledWith[0])
+	assert.Equal(t, "phone", sessions.RefreshSessionCalledWith[1])
+	user := tok.GenerateTokenResponseCalledWith[0].(interfaces.UserModelInterface)
+	assert.Equal(t, "phone", user.GetDeviceToken())
+}
+
+func TestAuthHandler_PostRefresh_legacyTokenService_success(t *testing.T) {
+	users := newTestUserLookup()
+	tok := newTestTokenService()
+	tok.MockValidateRefreshToken = func(tokenString string) (string, error) {
+		return "encrypted-user-id", nil
+	}
+	sessions := &mocks.MockSessionService{}
+	// Only the original TokenServiceInterface methods, so no device in the claims
+	legacy := struct {
+		interfaces.TokenServiceInterface
+	}{tok}
+	h := NewAuthHandler(users, legacy, sessions)
+
+	w := test_helpers.ServeRequest("POST", "/refresh", h.PostRefresh, models.PostRefreshRequest{
+		RefreshToken: "refresh",
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []interface{}{"refresh"}, tok.ValidateRefreshTokenCalledWith)
+	assert.Equal(t, []interface{}{"encrypted-user-id"}, tok.DecryptUserIDCalledWith)
+	// Falls back to the user model's device
+	assert.Equal(t, "device-token", sessions.RefreshSessionCalledWith[1])
+	user := tok.GenerateTokenResponseCalledWith[0].(interfaces.UserModelInterface)
+	assert.Equal(t, "device-token", user.GetDeviceToken())
+}
+
+func TestAuthHandler_stringUserIds_success(t *testing.T) {
+	users := &mocks.MockStringUserLookup{}
+	users.MockFindUserByUsername = func(username string) (interfaces.StringUserModelInterface, string, string, error) {
+		return &mocks.StringUserMock{}, testPasswordHash, testPasswordSalt, nil
+	}
+	tok := newTestTokenService()
+	tok.MockDecryptUserIDString = func(encryptedUserID string) (string, error) {
+		return "2b1c3f9e-5d4a-4e0b-9a57-6f1d2c3b4a5e", nil
+	}
+	sessions := &mocks.MockSessionService{}
+	h := NewStringUserAuthHandler(users, tok, sessions)
+
+	w := test_helpers.ServeRequest("POST", "/login", h.PostLogin, models.PostLoginRequest{
+		Username: "user",
+		Password: testPassword,
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotNil(t, tok.GenerateStringUserTokenResponseCalledWith)
+	assert.Nil(t, tok.GenerateTokenResponseCalledWith)
+	assert.Equal(t, "2b1c3f9e-5d4a-4e0b-9a57-6f1d2c3b4a5e", sessions.CreateSessionCalledWith[0])
+
+	w = test_helpers.ServeRequest("POST", "/refresh", h.PostRefresh, models.PostRefreshRequest{
+		RefreshToken: "refresh",
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []interface{}{"2b1c3f9e-5d4a-4e0b-9a57-6f1d2c3b4a5e"}, users.FindUserByIdCalledWith)
+	assert.Nil(t, tok.DecryptUserIDCalledWith)
+}
+
+func TestAuthHandler_PostRefresh_revokedDeviceOfTwo_401(t *testing.T) {
+	db := database.NewTestDatabase(t, services.SessionMigration)
+	sessions := services.NewSessionService(db)
+	tok := services.NewTokenService(&settings.BaseSettings{
+		EncryptionKey:      "6cad110bda2bb75863aae0b7e6cef9719c729c97287985acc101c237e9165045",
+		JwtHmacKey:         "39ec2ad652a6b32df6664711e13e74f6388f2a67550397e8b97212471e35042e",
+		JwtAccessTokenTTL:  1,
+		JwtRefreshTokenTTL: 2,
+	})
+
+	users := newTestUserLookup()
+	users.MockFindUserById = func(userId int) (interfaces.UserModelInterface, error) {
+		return &mocks.UserMock{}, nil
+	}
+	h := NewAuthHandler(users, tok, sessions)
+
+	login := func(device string) models.TokenResponse {
+		w := test_helpers.ServeRequest("POST", "/login", h.PostLogin, models.PostLoginRequest{
+			Username:    "user",
+			Password:    testPassword,
+			DeviceToken: device,
+		})
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response models.TokenResponse
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return response
+	}
+	phone := login("phone")
+	laptop := login("laptop")
+
+	err := sessions.RevokeDeviceSession("1", "phone")
+	assert.Nil(t, err)
+
+	w := test_helpers.ServeRequest("POST", "/refresh", h.PostRefresh, models.PostRefreshRequest{
+		RefreshToken: phone.RefreshToken,
+	})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = test_helpers.ServeRequest("POST", "/refresh", h.PostRefresh, models.PostRefreshRequest{
+		RefreshToken: laptop.RefreshToken,
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAuthHandler_PostRefresh_missingToken_400(t *testing.T) {
+	h := NewAuthHandler(newTestUserLookup(), newTestTokenService(), nil)
+
+	w := test_helpers.ServeRequest("POST", "/refresh", h.PostRefresh, models.PostRefreshRequest{})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAuthHandler_PostRefresh_invalidBody_400(t *testing.T) {
+	h := NewAuthHandler(newTestUserLookup(), newTestTokenService(), nil)
+
+	w := test_helpers.ServeRequest("POST", "/refresh", h.PostRefresh, "garbage")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAuthHandler_PostRefresh_invalidToken_401(t *testing.T) {
+	tok := newTestTokenService()
+	tok.MockValidateRefreshToken = func(tokenString string) (string, error) {
+		return "", errors.New("boom")
+	}
+	h := NewAuthHandler(newTestUserLookup(), tok, nil)
+
+	w := test_helpers.ServeRequest("POST", "/refresh", h.PostRefresh, models.PostRefreshRequest{
+		RefreshToken: "refresh",
+	})
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Nil(t, tok.DecryptUserIDCalledWith)
+}
+
+func TestAuthHandler_PostRefresh_decryptError_401(t *testing.T) {
+	tok := newTestTokenService()
+	tok.MockDecryptUserID = func(encryptedUserID string) (int, error) {
+		return 0, errors.New("boom")
+	}
+	users := newTestUserLookup()
+	h := NewAuthHandler(users, tok, nil)
+
+	w := test_helpers.ServeRequest("POST", "/refresh", h.PostRefresh, models.PostRefreshRequest{
+		RefreshToken: "refresh",
+	})
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Nil(t, users.FindUserByIdCalledWith)
+}
+
+func TestAuthHandler_PostRefresh_lookupError_500(t *testing.T) {
+	users := &mocks.MockUserLookup{}
+	users.MockFindUserById = func(userId int) (interfaces.UserModelInterface, error) {
+		return nil, errors.New("boom")
+	}
+	h := NewAuthHandler(users, newTestTokenService(), nil)
+
+	w := test_helpers.ServeRequest("POST", "/refresh", h.PostRefresh, models.PostRefreshRequest{
+		RefreshToken: "refresh",
+	})
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestAuthHandler_PostRefresh_unknownUser_401(t *testing.T) {
+	users := &mocks.MockUserLookup{}
+	users.MockFindUserById = func(userId int) (interfaces.UserModelInterface, error) {
+		return nil, nil
+	}
+	h := NewAuthHandler(users, newTestTokenService(), nil)
+
+	w := test_helpers.ServeRequest("POST", "/refresh", h.PostRefresh, models.PostRefreshRequest{
+		RefreshToken: "refresh",
+	})
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAuthHandler_PostRefresh_revokedSession_401(t *testing.T) {
+	tok := newTestTokenService()
+	sessions := &mocks.MockSessionService{}
+	sessions.MockRefreshSession = func(userId, deviceToken, userAgent, ipAddress string) (*models.Session, error) {
+		return nil, services.ErrSessionRevoked
+	}
+	h := NewAuthHandler(newTestUserLookup(), tok, sessions)
+
+	w := test_helpers.ServeRequest("POST", "/refresh", h.PostRefresh, models.PostRefreshRequest{
+		RefreshToken: "refresh",
+	})
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Nil(t, tok.GenerateTokenResponseCalledWith)
+}
+
+func TestAuthHandler_PostRefresh_sessionError_500(t *testing.T) {
+	sessions := &mocks.MockSessionService{}
+	sessions.MockRefreshSession = func(userId, deviceToken, userAgent, ipAddress string) (*models.Session, error) {
+		return nil, errors.New("boom")
+	}
+	h := NewAuthHandler(newTestUserLookup(), newTestTokenService(), sessions)
+
+	w := test_helpers.ServeRequest("POST", "/refresh", h.PostRefresh, models.PostRefreshRequest{
+		RefreshToken: "refresh",
+	})
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestAuthHandler_PostRefresh_generateTokenError_500(t *testing.T) {
+	tok := newTestTokenService()
+	tok.MockGenerateTokenResponse = func(user interfaces.UserModelInterface) (*models.TokenResponse, error) {
+		return nil, errors.New("boom")
+	}
+	h := NewAuthHandler(newTestUserLookup(), tok, nil)
+
+	w := test_helpers.ServeRequest("POST", "/refresh", h.PostRefresh, models.PostRefreshRequest{
+		RefreshToken: "refresh",
+	})
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestAuthHandler_PostLogout_success(t *testing.T) {
+	sessions := &mocks.MockSessionService{}
+	h := NewAuthHandler(newTestUserLookup(), newTestTokenService(), sessions)
+
+	w := test_helpers.ServeRouteRequest(
+		"POST", "/logout", "/logout",
+		[]gin.HandlerFunc{setAuthContext(7, "device-b"), h.PostLogout},
+		nil,
+	)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, []interface{}{"7", "device-b"}, sessions.RevokeDeviceSessionCalledWith)
+}
+
+func TestAuthHandler_PostLogout_noSession_success(t *testing.T) {
+	sessions := &mocks.MockSessionService{}
+	sessions.MockRevokeDeviceSession = func(userId, deviceToken string) error {
+		return services.ErrSessionNotFound
+	}
+	h := NewAuthHandler(newTestUserLookup(), newTestTokenService(), sessions)
+
+	w := test_helpers.ServeRouteRequest(
+		"POST", "/logout", "/logout",
+		[]gin.HandlerFunc{setAuthContext(7, "device-b"), h.PostLogout},
+		nil,
+	)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestAuthHandler_PostLogout_sessionError_500(t *testing.T) {
+	sessions := &mocks.MockSessionService{}
+	sessions.MockRevokeDeviceSession = func(userId, deviceToken string) error {
+		return errors.New("boom")
+	}
+	h := NewAuthHandler(newTestUserLookup(), newTestTokenService(), sessions)
+
+	w := test_helpers.ServeRouteRequest(
+		"POST", "/logout", "/logout",
+		[]gin.HandlerFunc{setAuthContext(7, "device-b"), h.PostLogout},
+		nil,
+	)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestAuthHandler_PostLogout_noUser_401(t *testing.T) {
+	h := NewAuthHandler(newTestUserLookup(), newTestTokenService(), nil)
+
+	w := test_helpers.ServeRequest("POST", "/logout", h.PostLogout, nil)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
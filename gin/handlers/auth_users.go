@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/Admiral-Piett/go-tools/gin/interfaces"
+	"github.com/Admiral-Piett/go-tools/gin/models"
+)
+
+// authUser is the app's user model as AuthHandler needs it, whatever its id type.
+// generateTokens issues tokens for deviceToken rather than the model's current device, so a
+// refreshed pair stays tied to the device the refresh token was issued to.
+type authUser struct {
+	id             string
+	deviceToken    string
+	generateTokens func(deviceToken string) (*models.TokenResponse, error)
+}
+
+// authUsers hides whether the app's user ids are ints or strings from AuthHandler.  Lookups
+// return a nil user when nothing matches.
+type authUsers interface {
+	findByUsername(username string) (user *authUser, hash string, salt string, err error)
+	findById(userId string) (*authUser, error)
+	decryptUserId(encryptedUserId string) (string, error)
+}
+
+// intUsers adapts UserLookupInterface and TokenServiceInterface
+type intUsers struct {
+	lookup       interfaces.UserLookupInterface
+	tokenService interfaces.TokenServiceInterface
+}
+
+func (u *intUsers) findByUsername(username string) (*authUser, string, string, error) {
+	user, hash, salt, err := u.lookup.FindUserByUsername(username)
+	if err != nil || user == nil {
+		return nil, "", "", err
+	}
+	return u.wrap(user), hash, salt, nil
+}
+
+func (u *intUsers) findById(userId string) (*authUser, error) {
+	id, err := strconv.Atoi(userId)
+	if err != nil {
+		return nil, err
+	}
+	user, err := u.lookup.FindUserById(id)
+	if err != nil || user == nil {
+		return nil, err
+	}
+	return u.wrap(user), nil
+}
+
+func (u *intUsers) decryptUserId(encryptedUserId string) (string, error) {
+	userId, err := u.tokenService.DecryptUserID(encryptedUserId)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(userId), nil
+}
+
+func (u *intUsers) wrap(user interfaces.UserModelInterface) *authUser {
+	return &authUser{
+		id:          strconv.Itoa(user.GetUserId()),
+		deviceToken: user.GetDeviceToken(),
+		generateTokens: func(deviceToken string) (*models.TokenResponse, error) {
+			return u.tokenService.GenerateTokenResponse(&deviceUser{user, deviceToken})
+		},
+	}
+}
+
+// stringUsers adapts StringUserLookupInterface and StringUserTokenServiceInterface
+type stringUsers struct {
+	lookup       interfaces.StringUserLookupInterface
+	tokenService interfaces.StringUserTokenServiceInterface
+}
+
+func (u *stringUsers) findByUsername(username string) (*authUser, string, string, error) {
+	user, hash, salt, err := u.lookup.FindUserByUsername(username)
+	if err != nil || user == nil {
+		return nil, "", "", err
+	}
+	return u.wrap(user), hash, salt, nil
+}
+
+func (u *stringUsers) findById(userId string) (*authUser, error) {
+	user, err := u.lookup.FindUserById(userId)
+	if err != nil || user == nil {
+		return nil, err
+	}
+	return u.wrap(user), nil
+}
+
+func (u *stringUsers) decryptUserId(encryptedUserId string) (string, error) {
+	return u.tokenService.DecryptUserIDString(encryptedUserId)
+}
+
+func (u *stringUsers) wrap(user interfaces.StringUserModelInterface) *authUser {
+	return &authUser{
+		id:          user.GetUserIdString(),
+		deviceToken: user.GetDeviceToken(),
+		generateTokens: func(deviceToken string) (*models.TokenResponse, error) {
+			return u.tokenService.GenerateStringUserTokenResponse(&stringDeviceUser{user, deviceToken})
+		},
+	}
+}
+
+// deviceUser overrides a user model's device token
+type deviceUser struct {
+	interfaces.UserModelInterface
+	deviceToken string
+}
+
+func (u *deviceUser) GetDeviceToken() string {
+	return u.deviceToken
+}
+
+// stringDeviceUser is deviceUser for string id models
+type stringDeviceUser struct {
+	interfaces.StringUserModelInterface
+	deviceToken string
+}
+
+func (u *stringDeviceUser) GetDeviceToken() string {
+	return u.deviceToken
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -17,10 +18,25 @@ type HealthCheckResponse struct {
 	GitSha    string    `json:"sha"`
 	Timestamp time.Time `json:"timestamp"`
 	Uptime    string    `json:"uptime"`
+
+	Details map[string]interface{} `json:"details,omitempty"`
 }
 
 var startTime = time.Now()
 
+var (
+	healthDetailsMu sync.RWMutex
+	healthDetails   = map[string]func() interface{}{}
+)
+
+// RegisterHealthDetail adds a section to the health check response, e.g. the concurrency
+// limiter's queue depth.  fn is called on every health check so it should be cheap.
+func RegisterHealthDetail(name string, fn func() interface{}) {
+	healthDetailsMu.Lock()
+	defer healthDetailsMu.Unlock()
+	healthDetails[name] = fn
+}
+
 // HealthCheck handles health check requests for Cloud Run
 func HealthCheck(c *gin.Context) {
 	uptime := time.Since(startTime).Round(time.Second)
@@ -34,6 +50,15 @@ func HealthCheck(c *gin.Context) {
 		Uptime:    uptime.String(),
 	}
 
+	healthDetailsMu.RLock()
+	if len(healthDetails) > 0 {
+		response.Details = make(map[string]interface{}, len(healthDetails))
+		for name, fn := range healthDetails {
+			response.Details[name] = fn()
+		}
+	}
+	healthDetailsMu.RUnlock()
+
 	log.Debug("Health check requested")
 	c.JSON(http.StatusOK, response)
 }
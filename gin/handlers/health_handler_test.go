@@ -32,3 +32,22 @@ func TestHealthCheck_Success(t *testing.T) {
 	assert.Equal(t, "local.0", response.Version)
 	assert.Equal(t, "git-sha", response.GitSha)
 }
+
+func TestHealthCheck_withDetails_success(t *testing.T) {
+	RegisterHealthDetail("queue", func() interface{} {
+		return map[string]int{"depth": 3}
+	})
+	defer func() {
+		healthDetailsMu.Lock()
+		delete(healthDetails, "queue")
+		healthDetailsMu.Unlock()
+	}()
+
+	w := test_helpers.ServeRequest("GET", "/health", HealthCheck, nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response HealthCheckResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"depth": float64(3)}, response.Details["queue"])
+}
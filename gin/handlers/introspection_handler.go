@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Admiral-Piett/go-tools/gin/interfaces"
+	"github.com/Admiral-Piett/go-tools/gin/models"
+	"github.com/Admiral-Piett/go-tools/gin/services"
+	"github.com/Admiral-Piett/go-tools/gin/utils"
+	"github.com/Admiral-Piett/go-tools/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	tokenTypeHintAccess  = "access_token"
+	tokenTypeHintRefresh = "refresh_token"
+)
+
+// IntrospectionHandler provides an RFC 7662 endpoint so services that can't validate our JWTs
+// themselves can ask us instead.  Callers authenticate as an OAuth client, the same way as at
+// the token endpoint, and the client must be granted `models.IntrospectScope`.  The session
+// service is optional - pass nil to skip session revocation checks.
+//
+//	router.POST("/oauth/introspect", h.PostIntrospect)
+type IntrospectionHandler struct {
+	clientService  interfaces.OAuthClientServiceInterface
+	tokenService   interfaces.TokenServiceInterface
+	sessionService interfaces.SessionServiceInterface
+}
+
+func NewIntrospectionHandler(
+	clientService interfaces.OAuthClientServiceInterface,
+	tokenService interfaces.TokenServiceInterface,
+	sessionService interfaces.SessionServiceInterface,
+) *IntrospectionHandler {
+	return &IntrospectionHandler{
+		clientService:  clientService,
+		tokenService:   tokenService,
+		sessionService: sessionService,
+	}
+}
+
+// PostIntrospect reports whether the `token` form field is active.  A `token_type_hint` only
+// changes which kind of token is tried first.  Tokens are also checked against session and
+// client revocation.  User tokens are reported without a `sub`, user ids are only ever handed
+// out encrypted.
+func (h *IntrospectionHandler) PostIntrospect(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	clientId, clientSecret, ok := clientCredentials(c)
+	if !ok {
+		c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+		oauthError(c, http.StatusUnauthorized, models.OAuthInvalidClient)
+		return
+	}
+	client, err := h.clientService.AuthenticateClient(clientId, clientSecret)
+	if errors.Is(err, services.ErrClientInvalid) {
+		logging.FromContext(c.Request.Context()).
+			WithError(err).
+			Warning("Authenticate Client Failure")
+		c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+		oauthError(c, http.StatusUnauthorized, models.OAuthInvalidClient)
+		return
+	}
+	if err != nil {
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Authenticate Client Failure")
+		oauthError(c, http.StatusInternalServerError, models.OAuthServerError)
+		return
+	}
+	if !models.HasScopes(client.ScopeList(), models.IntrospectScope) {
+		logging.FromContext(c.Request.Context()).
+			WithField("client_id", clientId).
+			Warning("Introspect Failure - Missing Scope")
+		oauthError(c, http.StatusForbidden, models.OAuthInsufficientScope)
+		return
+	}
+
+	token := c.PostForm("token")
+	if token == "" {
+		oauthError(c, http.StatusBadRequest, models.OAuthInvalidRequest)
+		return
+	}
+
+	introspect := []func(string) (*models.IntrospectionResponse, error){
+		h.introspectAccessToken,
+		h.introspectRefreshToken,
+	}
+	if c.PostForm("token_type_hint") == tokenTypeHintRefresh {
+		introspect[0], introspect[1] = introspect[1], introspect[0]
+	}
+
+	for _, fn := range introspect {
+		response, err := fn(token)
+		if err != nil {
+			logging.FromContext(c.Request.Context()).
+				WithError(err).
+				Error("Introspect Token Failure")
+			oauthError(c, http.StatusInternalServerError, models.OAuthServerError)
+			return
+		}
+		if response != nil {
+			c.JSON(http.StatusOK, response)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, models.IntrospectionResponse{Active: false})
+}
+
+// introspectAccessToken returns nil if the token isn't an active access token.  Errors are
+// only returned when a revocation check itself fails.
+func (h *IntrospectionHandler) introspectAccessToken(
+	token string,
+) (*models.IntrospectionResponse, error) {
+	claims, err := h.tokenService.ValidateAccessToken(token)
+	if err != nil {
+		return nil, nil
+	}
+
+	response := &models.IntrospectionResponse{
+		Active:    true,
+		Scope:     claims.Scope,
+		TokenType: tokenTypeHintAccess,
+		Exp:       claims.ExpiresAt,
+		Iat:       claims.IssuedAt,
+		Nbf:       claims.NotBefore,
+		Iss:       claims.Issuer,
+	}
+
+	// Client credentials token
+	if claims.ClientId != "" && claims.EncryptedUserID == "" {
+		client, err := h.clientService.GetClient(claims.ClientId)
+		if errors.Is(err, services.ErrClientNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if client.RevokedAt != nil {
+			return nil, nil
+		}
+		response.ClientId = claims.ClientId
+		response.Sub = claims.ClientId
+		return response, nil
+	}
+
+	active, err := h.isSessionActive(claims.EncryptedUserID, claims.DeviceToken)
+	if err != nil || !active {
+		return nil, err
+	}
+	return response, nil
+}
+
+// introspectRefreshToken returns nil if the token isn't a valid refresh token
+func (h *IntrospectionHandler) introspectRefreshToken(
+	token string,
+) (*models.IntrospectionResponse, error) {
+	claims, err := utils.ValidateRefreshTokenClaims(h.tokenService, token)
+	if err != nil {
+		return nil, nil
+	}
+	// Without the device the token can't be matched to a session, so only its signature and
+	// expiry are checked
+	if _, ok := h.tokenService.(interfaces.RefreshClaimsValidator); ok {
+		active, err := h.isSessionActive(claims.Id, claims.DeviceToken)
+		if err != nil || !active {
+			return nil, err
+		}
+	}
+	return &models.IntrospectionResponse{
+		Active:    true,
+		TokenType: tokenTypeHintRefresh,
+		Exp:       claims.ExpiresAt,
+		Iat:       claims.IssuedAt,
+		Iss:       claims.Issuer,
+	}, nil
+}
+
+// isSessionActive checks the session a user token was issued for hasn't been revoked, the same
+// check `AuthHandler.PostRefresh` makes.  Without a session service every session is active.
+func (h *IntrospectionHandler) isSessionActive(
+	encryptedUserId string,
+	deviceToken string,
+) (bool, error) {
+	userId, err := utils.DecryptUserIdString(h.tokenService, encryptedUserId)
+	if err != nil {
+		return false, nil
+	}
+	if h.sessionService == nil {
+		return true, nil
+	}
+	return h.sessionService.IsSessionActive(userId, deviceToken)
+}
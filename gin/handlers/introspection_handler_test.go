@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Admiral-Piett/go-tools/gin/mocks"
+	"github.com/Admiral-Piett/go-tools/gin/models"
+	"github.com/Admiral-Piett/go-tools/gin/services"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestIntrospectionTokenService() *mocks.MockTokenService {
+	tok := &mocks.MockTokenService{}
+	tok.MockValidateAccessToken = func(tokenString string) (*models.AuthClaims, error) {
+		return &models.AuthClaims{
+			EncryptedUserID: "encrypted-user-id",
+			DeviceToken:     "device-token",
+			StandardClaims: jwt.StandardClaims{
+				ExpiresAt: 1700000900,
+				IssuedAt:  1700000000,
+				Issuer:    "go-tools",
+				Subject:   "access",
+			},
+		}, nil
+	}
+	tok.MockValidateRefreshToken = func(tokenString string) (string, error) {
+		return "", errors.New("token invalid")
+	}
+	tok.MockDecryptUserIDString = func(encryptedUserID string) (string, error) {
+		return "7", nil
+	}
+	return tok
+}
+
+func newTestIntrospectionClientService() *mocks.MockOAuthClientService {
+	clients := &mocks.MockOAuthClientService{}
+	clients.MockAuthenticateClient = func(clientId, clientSecret string) (*models.OAuthClient, error) {
+		return &models.OAuthClient{ClientId: clientId, Scopes: models.IntrospectScope}, nil
+	}
+	return clients
+}
+
+func decodeIntrospection(t *testing.T, body []byte) models.IntrospectionResponse {
+	var response models.IntrospectionResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		t.Fatal(err)
+	}
+	return response
+}
+
+func TestIntrospectionHandler_PostIntrospect_accessToken_success(t *testing.T) {
+	clients := newTestIntrospectionClientService()
+	sessions := &mocks.MockSessionService{}
+	h := NewIntrospectionHandler(clients, newTestIntrospectionTokenService(), sessions)
+
+	w := serveFormRequest(h.PostIntrospect, url.Values{"token": {"access"}}, "client-id", "secret")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	response := decodeIntrospection(t, w.Body.Bytes())
+	assert.True(t, response.Active)
+	// User ids aren't disclosed
+	assert.Empty(t, response.Sub)
+	assert.NotContains(t, w.Body.String(), `"sub"`)
+	assert.Equal(t, "access_token", response.TokenType)
+	assert.Equal(t, int64(1700000900), response.Exp)
+	assert.Equal(t, int64(1700000000), response.Iat)
+	assert.Equal(t, "go-tools", response.Iss)
+
+	assert.Equal(t, []interface{}{"client-id", "secret"}, clients.AuthenticateClientCalledWith)
+	assert.Equal(t, []interface{}{"7", "device-token"}, sessions.IsSessionActiveCalledWith)
+}
+
+func TestIntrospectionHandler_PostIntrospect_withoutSessionService_success(t *testing.T) {
+	h := NewIntrospectionHandler(newTestIntrospectionClientService(), newTestIntrospectionTokenService(), nil)
+
+	w := serveFormRequest(h.PostIntrospect, url.Values{"token": {"access"}}, "client-id", "secret")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, decodeIntrospection(t, w.Body.Bytes()).Active)
+}
+
+func TestIntrospectionHandler_PostIntrospect_revokedSession_inactive(t *testing.T) {
+	sessions := &mocks.MockSessionService{}
+	sessions.MockIsSessionActive = func(userId, deviceToken string) (bool, error) {
+		return false, nil
+	}
+	h := NewIntrospectionHandler(newTestIntrospectionClientService(), newTestIntrospectionTokenService(), sessions)
+
+	w := serveFormRequest(h.PostIntrospect, url.Values{"token": {"access"}}, "client-id", "secret")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"active": false}`, w.Body.String())
+}
+
+func TestIntrospectionHandler_PostIntrospect_sessionCheckError_500(t *testing.T) {
+	sessions := &mocks.MockSessionService{}
+	sessions.MockIsSessionActive = func(userId, deviceToken string) (bool, error) {
+		return false, errors.New("boom")
+	}
+	h := NewIntrospectionHandler(newTestIntrospectionClientService(), newTestIntrospectionTokenService(), sessions)
+
+	w := serveFormRequest(h.PostIntrospect, url.Values{"token": {"access"}}, "client-id", "secret")
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestIntrospectionHandler_PostIntrospect_clientToken_success(t *testing.T) {
+	tok := newTestIntrospectionTokenService()
+	tok.MockValidateAccessToken = func(tokenString string) (*models.AuthClaims, error) {
+		return &models.AuthClaims{ClientId: "batch-jobs", Scope: "jobs:read"}, nil
+	}
+	clients := newTestIntrospectionClientService()
+	h := NewIntrospectionHandler(clients, tok, &mocks.MockSessionService{})
+
+	w := serveFormRequest(h.PostIntrospect, url.Values{"token": {"access"}}, "client-id", "secret")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	response := decodeIntrospection(t, w.Body.Bytes())
+	assert.True(t, response.Active)
+	assert.Equal(t, "batch-jobs", response.ClientId)
+	assert.Equal(t, "batch-jobs", response.Sub)
+	assert.Equal(t, "jobs:read", response.Scope)
+	assert.Equal(t, []interface{}{"batch-jobs"}, clients.GetClientCalledWith)
+	assert.Nil(t, tok.DecryptUserIDStringCalledWith)
+}
+
+func TestIntrospectionHandler_PostIntrospect_revokedClientToken_inactive(t *testing.T) {
+	tok := newTestIntrospectionTokenService()
+	tok.MockValidateAccessToken = func(tokenString string) (*models.AuthClaims, error) {
+		return &models.AuthClaims{ClientId: "batch-jobs", Scope: "jobs:read"}, nil
+	}
+	clients := newTestIntrospectionClientService()
+	clients.MockGetClient = func(clientId string) (*models.OAuthClient, error) {
+		revokedAt := time.Now()
+		return &models.OAuthClient{ClientId: clientId, RevokedAt: &revokedAt}, nil
+	}
+	h := NewIntrospectionHandler(clients, tok, nil)
+
+	w := serveFormRequest(h.PostIntrospect, url.Values{"token": {"access"}}, "client-id", "secret")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"active": false}`, w.Body.String())
+}
+
+func TestIntrospectionHandler_PostIntrospect_refreshToken_success(t *testing.T) {
+	tok := newTestIntrospectionTokenService()
+	tok.MockValidateRefreshTokenClaims = func(tokenString string) (*models.RefreshClaims, error) {
+		return &models.RefreshClaims{
+			DeviceToken:    "device-token",
+			StandardClaims: jwt.StandardClaims{Id: "encrypted-user-id"},
+		}, nil
+	}
+	sessions := &mocks.MockSessionService{}
+	h := NewIntrospectionHandler(newTestIntrospectionClientService(), tok, sessions)
+
+	w := serveFormRequest(h.PostIntrospect, url.Values{
+		"token":           {"refresh"},
+		"token_type_hint": {"refresh_token"},
+	}, "client-id", "secret")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	response := decodeIntrospection(t, w.Body.Bytes())
+	assert.True(t, response.Active)
+	assert.Empty(t, response.Sub)
+	assert.Equal(t, "refresh_token", response.TokenType)
+	assert.Nil(t, tok.ValidateAccessTokenCalledWith)
+	assert.Equal(t, []interface{}{"7", "device-token"}, sessions.IsSessionActiveCalledWith)
+}
+
+func TestIntrospectionHandler_PostIntrospect_refreshTokenRevokedSession_inactive(t *testing.T) {
+	tok := newTestIntrospectionTokenService()
+	tok.MockValidateRefreshTokenClaims = func(tokenString string) (*models.RefreshClaims, error) {
+		return &models.RefreshClaims{
+			DeviceToken:    "device-token",
+			StandardClaims: jwt.StandardClaims{Id: "encrypted-user-id"},
+		}, nil
+	}
+	sessions := &mocks.MockSessionService{}
+	sessions.MockIsSessionActive = func(userId, deviceToken string) (bool, error) {
+		return false, nil
+	}
+	h := NewIntrospectionHandler(newTestIntrospectionClientService(), tok, sessions)
+
+	w := serveFormRequest(h.PostIntrospect, url.Values{
+		"token":           {"refresh"},
+		"token_type_hint": {"refresh_token"},
+	}, "client-id", "secret")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"active": false}`, w.Body.String())
+}
+
+func TestIntrospectionHandler_PostIntrospect_invalidToken_inactive(t *testing.T) {
+	tok := newTestIntrospectionTokenService()
+	tok.MockValidateAccessToken = func(tokenString string) (*models.AuthClaims, error) {
+		return nil, errors.New("token invalid")
+	}
+	h := NewIntrospectionHandler(newTestIntrospectionClientService(), tok, nil)
+
+	w := serveFormRequest(h.PostIntrospect, url.Values{"token": {"garbage"}}, "client-id", "secret")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"active": false}`, w.Body.String())
+	assert.Equal(t, []interface{}{"garbage"}, tok.ValidateRefreshTokenClaimsCalledWith)
+}
+
+func TestIntrospectionHandler_PostIntrospect_missingToken_400(t *testing.T) {
+	h := NewIntrospectionHandler(newTestIntrospectionClientService(), newTestIntrospectionTokenService(), nil)
+
+	w := serveFormRequest(h.PostIntrospect, url.Values{}, "client-id", "secret")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), models.OAuthInvalidRequest)
+}
+
+func TestIntrospectionHandler_PostIntrospect_missingScope_403(t *testing.T) {
+	tok := newTestIntrospectionTokenService()
+	h := NewIntrospectionHandler(newTestClientService(), tok, nil)
+
+	w := serveFormRequest(h.PostIntrospect, url.Values{"token": {"access"}}, "client-id", "secret")
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), models.OAuthInsufficientScope)
+	assert.Nil(t, tok.ValidateAccessTokenCalledWith)
+}
+
+func TestIntrospectionHandler_PostIntrospect_missingClient_401(t *testing.T) {
+	clients := newTestIntrospectionClientService()
+	h := NewIntrospectionHandler(clients, newTestIntrospectionTokenService(), nil)
+
+	w := serveFormRequest(h.PostIntrospect, url.Values{"token": {"access"}}, "", "")
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Nil(t, clients.AuthenticateClientCalledWith)
+}
+
+func TestIntrospectionHandler_PostIntrospect_invalidClient_401(t *testing.T) {
+	clients := newTestIntrospectionClientService()
+	clients.MockAuthenticateClient = func(clientId, clientSecret string) (*models.OAuthClient, error) {
+		return nil, services.ErrClientInvalid
+	}
+	tok := newTestIntrospectionTokenService()
+	h := NewIntrospectionHandler(clients, tok, nil)
+
+	w := serveFormRequest(h.PostIntrospect, url.Values{"token": {"access"}}, "client-id", "wrong")
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), models.OAuthInvalidClient)
+	assert.Nil(t, tok.ValidateAccessTokenCalledWith)
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Admiral-Piett/go-tools/gin/interfaces"
+	"github.com/Admiral-Piett/go-tools/gin/models"
+	"github.com/Admiral-Piett/go-tools/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultLogLevelTTL = 15 * time.Minute
+	maxLogLevelTTL     = 24 * time.Hour
+)
+
+// LogLevelHandler lets operators change the log level of a running instance, e.g. turning on
+// debug logs while chasing an incident.  Overrides always expire, reverting to the configured
+// level, so a forgotten one can't flood the logs for good.  Lock it down to admins:
+//
+//	admin := router.Group("/admin",
+//		authMiddleware.RequireAuth(middleware.AllowClients()),
+//		authMiddleware.RequireScopes("admin"),
+//	)
+//	h := handlers.NewLogLevelHandler(logging.Levels())
+//	admin.GET("/log-level", h.GetLogLevel)
+//	admin.PUT("/log-level", h.PutLogLevel)
+//	admin.DELETE("/log-level", h.DeleteLogLevel)
+//
+// Each instance has its own level, so with several replicas the override only applies to the
+// one that served the request.
+type LogLevelHandler struct {
+	levels interfaces.LogLevelControllerInterface
+}
+
+func NewLogLevelHandler(levels interfaces.LogLevelControllerInterface) *LogLevelHandler {
+	return &LogLevelHandler{
+		levels: levels,
+	}
+}
+
+// GetLogLevel returns the current level, the one it reverts to and when
+func (h *LogLevelHandler) GetLogLevel(c *gin.Context) {
+	c.JSON(http.StatusOK, h.levels.Status())
+}
+
+// PutLogLevel overrides the level for `ttl_seconds`, 15 minutes by default and at most a day
+func (h *LogLevelHandler) PutLogLevel(c *gin.Context) {
+	request := models.PutLogLevelRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponses.BadRequest)
+		return
+	}
+	level, err := logging.ParseLevel(request.Level)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponses.ValidationError)
+		return
+	}
+	ttl := time.Duration(request.TtlSeconds) * time.Second
+	if request.TtlSeconds == 0 {
+		ttl = defaultLogLevelTTL
+	}
+	if ttl <= 0 || ttl > maxLogLevelTTL {
+		c.JSON(http.StatusBadRequest, models.ErrorResponses.ValidationError)
+		return
+	}
+
+	status := h.levels.SetLevel(level, ttl)
+	logging.FromContext(c.Request.Context()).
+		WithField("level", status.Level).
+		WithField("ttl_seconds", int(ttl.Seconds())).
+		Warning("Log Level Overridden")
+	c.JSON(http.StatusOK, status)
+}
+
+// DeleteLogLevel drops any override now
+func (h *LogLevelHandler) DeleteLogLevel(c *gin.Context) {
+	status := h.levels.Reset()
+	logging.FromContext(c.Request.Context()).
+		WithField("level", status.Level).
+		Warning("Log Level Override Cleared")
+	c.JSON(http.StatusOK, status)
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Admiral-Piett/go-tools/gin/mocks"
+	"github.com/Admiral-Piett/go-tools/gin/models"
+	"github.com/Admiral-Piett/go-tools/gin/test_helpers"
+	"github.com/Admiral-Piett/go-tools/logging"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogLevelHandler_GetLogLevel_success(t *testing.T) {
+	levels := &mocks.MockLogLevelController{}
+	h := NewLogLevelHandler(levels)
+
+	w := test_helpers.ServeRequest("GET", "/admin/log-level", h.GetLogLevel, nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, levels.StatusCalled)
+	assert.JSONEq(t, `{"level":"info","base_level":"info"}`, w.Body.String())
+}
+
+func TestLogLevelHandler_PutLogLevel_success(t *testing.T) {
+	levels := &mocks.MockLogLevelController{}
+	h := NewLogLevelHandler(levels)
+
+	w := test_helpers.ServeRequest("PUT", "/admin/log-level", h.PutLogLevel, models.PutLogLevelRequest{
+		Level:      "DEBUG",
+		TtlSeconds: 300,
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []interface{}{log.DebugLevel, 5 * time.Minute}, levels.SetLevelCalledWith)
+
+	var response logging.LevelStatus
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Nil(t, err)
+	assert.Equal(t, "debug", response.Level)
+}
+
+func TestLogLevelHandler_PutLogLevel_defaultTTL_success(t *testing.T) {
+	levels := &mocks.MockLogLevelController{}
+	h := NewLogLevelHandler(levels)
+
+	w := test_helpers.ServeRequest("PUT", "/admin/log-level", h.PutLogLevel, models.PutLogLevelRequest{
+		Level: "WARNING",
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []interface{}{log.WarnLevel, 15 * time.Minute}, levels.SetLevelCalledWith)
+}
+
+func TestLogLevelHandler_PutLogLevel_unknownLevel_400(t *testing.T) {
+	levels := &mocks.MockLogLevelController{}
+	h := NewLogLevelHandler(levels)
+
+	w := test_helpers.ServeRequest("PUT", "/admin/log-level", h.PutLogLevel, models.PutLogLevelRequest{
+		Level: "LOUD",
+	})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Nil(t, levels.SetLevelCalledWith)
+}
+
+func TestLogLevelHandler_PutLogLevel_ttlTooLong_400(t *testing.T) {
+	levels := &mocks.MockLogLevelController{}
+	h := NewLogLevelHandler(levels)
+
+	w := test_helpers.ServeRequest("PUT", "/admin/log-level", h.PutLogLevel, models.PutLogLevelRequest{
+		Level:      "DEBUG",
+		TtlSeconds: 2 * 24 * 60 * 60,
+	})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Nil(t, levels.SetLevelCalledWith)
+}
+
+func TestLogLevelHandler_PutLogLevel_badBody_400(t *testing.T) {
+	h := NewLogLevelHandler(&mocks.MockLogLevelController{})
+
+	w := test_helpers.ServeRequest("PUT", "/admin/log-level", h.PutLogLevel, "garbage")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestLogLevelHandler_DeleteLogLevel_success(t *testing.T) {
+	levels := &mocks.MockLogLevelController{}
+	h := NewLogLevelHandler(levels)
+
+	w := test_helpers.ServeRequest("DELETE", "/admin/log-level", h.DeleteLogLevel, nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, levels.ResetCalled)
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Admiral-Piett/go-tools/gin/interfaces"
+	"github.com/Admiral-Piett/go-tools/gin/models"
+	"github.com/Admiral-Piett/go-tools/gin/services"
+	"github.com/Admiral-Piett/go-tools/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+const grantTypeClientCredentials = "client_credentials"
+
+// OAuthHandler provides an RFC 6749 token endpoint for service accounts.  Only the client
+// credentials grant is supported, users log in through `AuthHandler`.
+//
+//	router.POST("/oauth/token", h.PostToken)
+type OAuthHandler struct {
+	clientService interfaces.OAuthClientServiceInterface
+	tokenService  interfaces.ClientTokenServiceInterface
+}
+
+func NewOAuthHandler(
+	clientService interfaces.OAuthClientServiceInterface,
+	tokenService interfaces.ClientTokenServiceInterface,
+) *OAuthHandler {
+	return &OAuthHandler{
+		clientService: clientService,
+		tokenService:  tokenService,
+	}
+}
+
+// PostToken exchanges client credentials for an access token.  Credentials are accepted via
+// HTTP Basic auth or the `client_id` and `client_secret` form fields.  If no scope is requested
+// the client gets every scope it's allowed.
+func (h *OAuthHandler) PostToken(c *gin.Context) {
+	// Token responses must never be cached - RFC 6749 section 5.1
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	if c.PostForm("grant_type") != grantTypeClientCredentials {
+		oauthError(c, http.StatusBadRequest, models.OAuthUnsupportedGrantType)
+		return
+	}
+
+	clientId, clientSecret, ok := clientCredentials(c)
+	if !ok {
+		oauthError(c, http.StatusBadRequest, models.OAuthInvalidRequest)
+		return
+	}
+
+	client, err := h.clientService.AuthenticateClient(clientId, clientSecret)
+	if errors.Is(err, services.ErrClientInvalid) {
+		logging.FromContext(c.Request.Context()).
+			WithError(err).
+			Warning("Authenticate Client Failure")
+		c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+		oauthError(c, http.StatusUnauthorized, models.OAuthInvalidClient)
+		return
+	}
+	if err != nil {
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Authenticate Client Failure")
+		oauthError(c, http.StatusInternalServerError, models.OAuthServerError)
+		return
+	}
+
+	scopes := client.ScopeList()
+	if requested := models.ParseScopes(c.PostForm("scope")); len(requested) > 0 {
+		if !models.HasScopes(scopes, requested...) {
+			logging.FromContext(c.Request.Context()).Warning("Client Token Failure - Scope Not Allowed")
+			oauthError(c, http.StatusBadRequest, models.OAuthInvalidScope)
+			return
+		}
+		scopes = requested
+	}
+
+	response, err := h.tokenService.GenerateClientTokenResponse(client.ClientId, scopes)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).
+			WithError(err).
+			Error("Generate Client Token Response Failure")
+		oauthError(c, http.StatusInternalServerError, models.OAuthServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.OAuthTokenResponse{
+		AccessToken: response.AccessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(math.Round(time.Until(response.ExpiresAt).Seconds())),
+		Scope:       models.JoinScopes(scopes),
+	})
+}
+
+// clientCredentials reads the client's id and secret, preferring HTTP Basic auth.  Basic
+// credentials are form-encoded before being base64'd - RFC 6749 section 2.3.1.
+func clientCredentials(c *gin.Context) (string, string, bool) {
+	if id, secret, ok := c.Request.BasicAuth(); ok {
+		id, idErr := url.QueryUnescape(id)
+		secret, secretErr := url.QueryUnescape(secret)
+		if idErr != nil || secretErr != nil {
+			return "", "", false
+		}
+		return id, secret, id != "" && secret != ""
+	}
+	id, secret := c.PostForm("client_id"), c.PostForm("client_secret")
+	return id, secret, id != "" && secret != ""
+}
+
+func oauthError(c *gin.Context, status int, code string) {
+	c.JSON(status, models.OAuthErrorResponse{Error: code})
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Admiral-Piett/go-tools/gin/mocks"
+	"github.com/Admiral-Piett/go-tools/gin/models"
+	"github.com/Admiral-Piett/go-tools/gin/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestClientService() *mocks.MockOAuthClientService {
+	clients := &mocks.MockOAuthClientService{}
+	clients.MockAuthenticateClient = func(clientId, clientSecret string) (*models.OAuthClient, error) {
+		return &models.OAuthClient{ClientId: clientId, Scopes: "jobs:read jobs:write"}, nil
+	}
+	return clients
+}
+
+func newTestClientTokenService() *mocks.MockTokenService {
+	tok := &mocks.MockTokenService{}
+	tok.MockGenerateClientTokenResponse = func(clientId string, scopes []string) (*models.TokenResponse, error) {
+		return &models.TokenResponse{
+			AccessToken: "access",
+			ExpiresAt:   time.Now().Add(15 * time.Minute),
+		}, nil
+	}
+	return tok
+}
+
+// serveFormRequest posts a form encoded body, as OAuth endpoints expect, with optional Basic auth
+func serveFormRequest(
+	handlerFunc gin.HandlerFunc,
+	form url.Values,
+	basicId, basicSecret string,
+) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/oauth", handlerFunc)
+
+	req := httptest.NewRequest("POST", "/oauth", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if basicId != "" {
+		req.SetBasicAuth(basicId, basicSecret)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestOAuthHandler_PostToken_basicAuth_success(t *testing.T) {
+	clients := newTestClientService()
+	tok := newTestClientTokenService()
+	h := NewOAuthHandler(clients, tok)
+
+	w := serveFormRequest(h.PostToken, url.Values{"grant_type": {"client_credentials"}}, "client-id", "secret")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	var response models.OAuthTokenResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Nil(t, err)
+	assert.Equal(t, "access", response.AccessToken)
+	assert.Equal(t, "Bearer", response.TokenType)
+	assert.InDelta(t, 900, response.ExpiresIn, 1)
+	assert.Equal(t, "jobs:read jobs:write", response.Scope)
+
+	assert.Equal(t, []interface{}{"client-id", "secret"}, clients.AuthenticateClientCalledWith)
+	assert.Equal(
+		t,
+		[]interface{}{"client-id", []string{"jobs:read", "jobs:write"}},
+		tok.GenerateClientTokenResponseCalledWith,
+	)
+}
+
+func TestOAuthHandler_PostToken_formCredentials_requestedScope_success(t *testing.T) {
+	clients := newTestClientService()
+	tok := newTestClientTokenService()
+	h := NewOAuthHandler(clients, tok)
+
+	w := serveFormRequest(h.PostToken, url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {"client-id"},
+		"client_secret": {"secret"},
+		"scope":         {"jobs:read"},
+	}, "", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []interface{}{"client-id", "secret"}, clients.AuthenticateClientCalledWith)
+	assert.Equal(
+		t,
+		[]interface{}{"client-id", []string{"jobs:read"}},
+		tok.GenerateClientTokenResponseCalledWith,
+	)
+}
+
+func TestOAuthHandler_PostToken_unsupportedGrantType_400(t *testing.T) {
+	clients := newTestClientService()
+	h := NewOAuthHandler(clients, newTestClientTokenService())
+
+	w := serveFormRequest(h.PostToken, url.Values{"grant_type": {"password"}}, "client-id", "secret")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), models.OAuthUnsupportedGrantType)
+	assert.Nil(t, clients.AuthenticateClientCalledWith)
+}
+
+func TestOAuthHandler_PostToken_missingCredentials_400(t *testing.T) {
+	clients := newTestClientService()
+	h := NewOAuthHandler(clients, newTestClientTokenService())
+
+	w := serveFormRequest(h.PostToken, url.Values{"grant_type": {"client_credentials"}}, "", "")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), models.OAuthInvalidRequest)
+	assert.Nil(t, clients.AuthenticateClientCalledWith)
+}
+
+func TestOAuthHandler_PostToken_invalidClient_401(t *testing.T) {
+	clients := newTestClientService()
+	clients.MockAuthenticateClient = func(clientId, clientSecret string) (*models.OAuthClient, error) {
+		return nil, services.ErrClientInvalid
+	}
+	tok := newTestClientTokenService()
+	h := NewOAuthHandler(clients, tok)
+
+	w := serveFormRequest(h.PostToken, url.Values{"grant_type": {"client_credentials"}}, "client-id", "wrong")
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), models.OAuthInvalidClient)
+	assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+	assert.Nil(t, tok.GenerateClientTokenResponseCalledWith)
+}
+
+func TestOAuthHandler_PostToken_authenticateError_500(t *testing.T) {
+	clients := newTestClientService()
+	clients.MockAuthenticateClient = func(clientId, clientSecret string) (*models.OAuthClient, error) {
+		return nil, errors.New("boom")
+	}
+	h := NewOAuthHandler(clients, newTestClientTokenService())
+
+	w := serveFormRequest(h.PostToken, url.Values{"grant_type": {"client_credentials"}}, "client-id", "secret")
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), models.OAuthServerError)
+}
+
+func TestOAuthHandler_PostToken_scopeNotAllowed_400(t *testing.T) {
+	tok := newTestClientTokenService()
+	h := NewOAuthHandler(newTestClientService(), tok)
+
+	w := serveFormRequest(h.PostToken, url.Values{
+		"grant_type": {"client_credentials"},
+		"scope":      {"jobs:read admin"},
+	}, "client-id", "secret")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), models.OAuthInvalidScope)
+	assert.Nil(t, tok.GenerateClientTokenResponseCalledWith)
+}
+
+func TestOAuthHandler_PostToken_generateTokenError_500(t *testing.T) {
+	tok := newTestClientTokenService()
+	tok.MockGenerateClientTokenResponse = func(clientId string, scopes []string) (*models.TokenResponse, error) {
+		return nil, errors.New("boom")
+	}
+	h := NewOAuthHandler(newTestClientService(), tok)
+
+	w := serveFormRequest(h.PostToken, url.Values{"grant_type": {"client_credentials"}}, "client-id", "secret")
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
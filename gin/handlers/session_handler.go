@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Admiral-Piett/go-tools/gin/interfaces"
+	"github.com/Admiral-Piett/go-tools/gin/models"
+	"github.com/Admiral-Piett/go-tools/gin/services"
+	"github.com/Admiral-Piett/go-tools/gin/utils"
+	"github.com/Admiral-Piett/go-tools/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SessionHandler lets a logged in user see and revoke their own sessions.  All routes must sit
+// behind `AuthMiddleware.RequireAuth`.
+//
+//	sessions := router.Group("/sessions", authMiddleware.RequireAuth())
+//	sessions.GET("", h.GetSessions)
+//	sessions.DELETE("", h.DeleteSessions)
+//	sessions.DELETE("/:id", h.DeleteSession)
+type SessionHandler struct {
+	sessionService interfaces.SessionServiceInterface
+}
+
+func NewSessionHandler(
+	sessionService interfaces.SessionServiceInterface,
+) *SessionHandler {
+	return &SessionHandler{
+		sessionService: sessionService,
+	}
+}
+
+// GetSessions lists the caller's active sessions, flagging the one making the request
+func (h *SessionHandler) GetSessions(c *gin.Context) {
+	userId, ok := utils.GetUserIdString(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponses.UnauthorizedError)
+		return
+	}
+
+	sessions, err := h.sessionService.ListActiveSessions(userId)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).WithError(err).Error("List Sessions Failure")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponses.GeneralError)
+		return
+	}
+
+	deviceToken, _ := utils.GetDeviceToken(c)
+	response := models.SessionsResponse{Sessions: []models.SessionResponse{}}
+	for _, s := range sessions {
+		response.Sessions = append(response.Sessions, models.SessionResponse{
+			Session: s,
+			Current: deviceToken != "" && s.DeviceToken == deviceToken,
+		})
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteSession revokes one of the caller's sessions by id
+func (h *SessionHandler) DeleteSession(c *gin.Context) {
+	userId, ok := utils.GetUserIdString(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponses.UnauthorizedError)
+		return
+	}
+
+	sessionId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponses.BadRequest)
+		return
+	}
+
+	err = h.sessionService.RevokeSession(userId, uint(sessionId))
+	if errors.Is(err, services.ErrSessionNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponses.NotFoundError)
+		return
+	}
+	if err != nil {
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Revoke Session Failure")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponses.GeneralError)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// DeleteSessions revokes every one of the caller's sessions, including the current one
+func (h *SessionHandler) DeleteSessions(c *gin.Context) {
+	userId, ok := utils.GetUserIdString(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponses.UnauthorizedError)
+		return
+	}
+
+	err := h.sessionService.RevokeAllSessions(userId)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Revoke All Sessions Failure")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponses.GeneralError)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
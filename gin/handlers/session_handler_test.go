@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Admiral-Piett/go-tools/gin/mocks"
+	"github.com/Admiral-Piett/go-tools/gin/models"
+	"github.com/Admiral-Piett/go-tools/gin/services"
+	"github.com/Admiral-Piett/go-tools/gin/test_helpers"
+	"github.com/Admiral-Piett/go-tools/gin/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// setAuthContext stands in for AuthMiddleware.RequireAuth
+func setAuthContext(userId int, deviceToken string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := utils.WithUserId(c.Request.Context(), userId)
+		ctx = utils.WithDeviceToken(ctx, deviceToken)
+		c.Request = c.Request.WithContext(ctx)
+	}
+}
+
+func TestSessionHandler_GetSessions_success(t *testing.T) {
+	svc := &mocks.MockSessionService{}
+	svc.MockListActiveSessions = func(userId string) ([]models.Session, error) {
+		return []models.Session{
+			{Id: 1, DeviceToken: "device-a"},
+			{Id: 2, DeviceToken: "device-b"},
+		}, nil
+	}
+	h := NewSessionHandler(svc)
+
+	w := test_helpers.ServeRouteRequest(
+		"GET", "/sessions", "/sessions",
+		[]gin.HandlerFunc{setAuthContext(7, "device-b"), h.GetSessions},
+		nil,
+	)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []interface{}{"7"}, svc.ListActiveSessionsCalledWith)
+
+	var response models.SessionsResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Nil(t, err)
+	assert.Len(t, response.Sessions, 2)
+	assert.False(t, response.Sessions[0].Current)
+	assert.True(t, response.Sessions[1].Current)
+}
+
+func TestSessionHandler_GetSessions_noUser_401(t *testing.T) {
+	h := NewSessionHandler(&mocks.MockSessionService{})
+
+	w := test_helpers.ServeRequest("GET", "/sessions", h.GetSessions, nil)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestSessionHandler_GetSessions_serviceError_500(t *testing.T) {
+	svc := &mocks.MockSessionService{}
+	svc.MockListActiveSessions = func(userId string) ([]models.Session, error) {
+		return nil, errors.New("boom")
+	}
+	h := NewSessionHandler(svc)
+
+	w := test_helpers.ServeRouteRequest(
+		"GET", "/sessions", "/sessions",
+		[]gin.HandlerFunc{setAuthContext(7, "device-b"), h.GetSessions},
+		nil,
+	)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestSessionHandler_DeleteSession_success(t *testing.T) {
+	svc := &mocks.MockSessionService{}
+	h := NewSessionHandler(svc)
+
+	w := test_helpers.ServeRouteRequest(
+		"DELETE", "/sessions/:id", "/sessions/3",
+		[]gin.HandlerFunc{setAuthContext(7, "device-b"), h.DeleteSession},
+		nil,
+	)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, []interface{}{"7", uint(3)}, svc.RevokeSessionCalledWith)
+}
+
+func TestSessionHandler_DeleteSession_invalidId_400(t *testing.T) {
+	svc := &mocks.MockSessionService{}
+	h := NewSessionHandler(svc)
+
+	w := test_helpers.ServeRouteRequest(
+		"DELETE", "/sessions/:id", "/sessions/garbage",
+		[]gin.HandlerFunc{setAuthContext(7, "device-b"), h.DeleteSession},
+		nil,
+	)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Nil(t, svc.RevokeSessionCalledWith)
+}
+
+func TestSessionHandler_DeleteSession_notFound_404(t *testing.T) {
+	svc := &mocks.MockSessionService{}
+	svc.MockRevokeSession = func(userId string, sessionId uint) error {
+		return services.ErrSessionNotFound
+	}
+	h := NewSessionHandler(svc)
+
+	w := test_helpers.ServeRouteRequest(
+		"DELETE", "/sessions/:id", "/sessions/3",
+		[]gin.HandlerFunc{setAuthContext(7, "device-b"), h.DeleteSession},
+		nil,
+	)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestSessionHandler_DeleteSessions_success(t *testing.T) {
+	svc := &mocks.MockSessionService{}
+	h := NewSessionHandler(svc)
+
+	w := test_helpers.ServeRouteRequest(
+		"DELETE", "/sessions", "/sessions",
+		[]gin.HandlerFunc{setAuthContext(7, "device-b"), h.DeleteSessions},
+		nil,
+	)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, []interface{}{"7"}, svc.RevokeAllSessionsCalledWith)
+}
+
+func TestSessionHandler_DeleteSessions_serviceError_500(t *testing.T) {
+	svc := &mocks.MockSessionService{}
+	svc.MockRevokeAllSessions = func(userId string) error {
+		return errors.New("boom")
+	}
+	h := NewSessionHandler(svc)
+
+	w := test_helpers.ServeRouteRequest(
+		"DELETE", "/sessions", "/sessions",
+		[]gin.HandlerFunc{setAuthContext(7, "device-b"), h.DeleteSessions},
+		nil,
+	)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -0,0 +1,19 @@
+package interfaces
+
+import (
+	"time"
+
+	"github.com/Admiral-Piett/go-tools/gin/models"
+)
+
+type APIKeyServiceInterface interface {
+	CreateAPIKey(
+		userId string,
+		name string,
+		scopes []string,
+		expiresAt *time.Time,
+	) (string, *models.APIKey, error)
+	ValidateAPIKey(rawKey string) (*models.APIKey, error)
+	ListAPIKeys(userId string) ([]models.APIKey, error)
+	RevokeAPIKey(userId string, prefix string) error
+}
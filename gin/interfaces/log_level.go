@@ -0,0 +1,16 @@
+package interfaces
+
+import (
+	"time"
+
+	"github.com/Admiral-Piett/go-tools/logging"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// LogLevelControllerInterface is implemented by `*logging.LevelController`
+type LogLevelControllerInterface interface {
+	Status() logging.LevelStatus
+	SetLevel(level log.Level, ttl time.Duration) logging.LevelStatus
+	Reset() logging.LevelStatus
+}
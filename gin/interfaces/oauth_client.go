@@ -0,0 +1,10 @@
+package interfaces
+
+import "github.com/Admiral-Piett/go-tools/gin/models"
+
+type OAuthClientServiceInterface interface {
+	CreateClient(name string, scopes []string) (string, *models.OAuthClient, error)
+	AuthenticateClient(clientId, clientSecret string) (*models.OAuthClient, error)
+	GetClient(clientId string) (*models.OAuthClient, error)
+	RevokeClient(clientId string) error
+}
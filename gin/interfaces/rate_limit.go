@@ -0,0 +1,16 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/ulule/limiter/v3"
+)
+
+// RateLimitStoreInterface is a limiter store that needs expired buckets cleaned up
+// periodically, since unlike memory or Redis nothing expires them for us.  Close stops any
+// background cleanup.
+type RateLimitStoreInterface interface {
+	limiter.Store
+	DeleteExpired(ctx context.Context) (int64, error)
+	Close() error
+}
@@ -0,0 +1,13 @@
+package interfaces
+
+import "github.com/Admiral-Piett/go-tools/gin/models"
+
+type SessionServiceInterface interface {
+	CreateSession(userId, deviceToken, userAgent, ipAddress string) (*models.Session, error)
+	RefreshSession(userId, deviceToken, userAgent, ipAddress string) (*models.Session, error)
+	IsSessionActive(userId, deviceToken string) (bool, error)
+	ListActiveSessions(userId string) ([]models.Session, error)
+	RevokeSession(userId string, sessionId uint) error
+	RevokeDeviceSession(userId, deviceToken string) error
+	RevokeAllSessions(userId string) error
+}
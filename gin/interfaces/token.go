@@ -10,3 +10,25 @@ type TokenServiceInterface interface {
 	ValidateRefreshToken(tokenString string) (string, error)
 	DecryptUserID(encryptedUserID string) (int, error)
 }
+
+// RefreshClaimsValidator is optional, token services implementing it return a refresh token's
+// full claims rather than just the encrypted user id, so refreshes can be checked against the
+// device the token was issued to.  See `utils.ValidateRefreshTokenClaims`.
+type RefreshClaimsValidator interface {
+	ValidateRefreshTokenClaims(tokenString string) (*models.RefreshClaims, error)
+}
+
+// ClientTokenServiceInterface issues access tokens to OAuth clients rather than users, see
+// `services.NewClientTokenService`
+type ClientTokenServiceInterface interface {
+	GenerateClientTokenResponse(clientId string, scopes []string) (*models.TokenResponse, error)
+}
+
+// StringUserTokenServiceInterface is the TokenServiceInterface for apps whose user models
+// implement StringUserModelInterface, see `services.NewStringUserTokenService`.  Token services
+// that only implement TokenServiceInterface work with int ids alone.
+type StringUserTokenServiceInterface interface {
+	TokenServiceInterface
+	GenerateStringUserTokenResponse(user StringUserModelInterface) (*models.TokenResponse, error)
+	DecryptUserIDString(encryptedUserID string) (string, error)
+}
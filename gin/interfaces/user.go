@@ -4,3 +4,16 @@ type UserModelInterface interface {
 	GetUserId() int
 	GetDeviceToken() string
 }
+
+// StringUserModelInterface is the UserModelInterface equivalent for models keyed by strings or
+// UUIDs, e.g. `return u.Id.String()`.  See StringUserTokenServiceInterface.
+type StringUserModelInterface interface {
+	GetUserIdString() string
+	GetDeviceToken() string
+}
+
+// UserIdType is the set of id types that can be parsed back out of a token or request context,
+// see `utils.ParseUserId` and `utils.GetUserIdAs`.
+type UserIdType interface {
+	~int | ~int64 | ~int32 | ~uint | ~uint64 | ~uint32 | ~string
+}
@@ -0,0 +1,17 @@
+package interfaces
+
+// UserLookupInterface is implemented by the app so the built in auth handlers can find users.
+// Both methods return a nil user (and nil error) when nothing matches.
+type UserLookupInterface interface {
+	// FindUserByUsername returns the user along with the hash and salt produced by
+	// `password.HashPassword` when their password was set.
+	FindUserByUsername(username string) (user UserModelInterface, hash string, salt string, err error)
+	FindUserById(userId int) (UserModelInterface, error)
+}
+
+// StringUserLookupInterface is the UserLookupInterface for user models keyed by strings or UUIDs,
+// see `handlers.NewStringUserAuthHandler`.
+type StringUserLookupInterface interface {
+	FindUserByUsername(username string) (user StringUserModelInterface, hash string, salt string, err error)
+	FindUserById(userId string) (StringUserModelInterface, error)
+}
@@ -2,9 +2,11 @@ package middleware
 
 import (
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/Admiral-Piett/go-tools/gin/utils"
+	"github.com/Admiral-Piett/go-tools/logging"
 
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
@@ -44,11 +46,13 @@ func AccessLogMiddleware() gin.HandlerFunc {
 			"duration_ms": float64(
 				duration.Nanoseconds(),
 			) / 1e6, // This will show partial milliseconds
-			"client_ip": c.ClientIP(),
+			"client_ip": utils.GetClientIP(c),
 		}
-		userId, ok := utils.GetUserId(c)
-		if ok {
-			fields["userId"] = userId
+		// Auth middleware normally adds userId to the log context, cover apps setting it themselves
+		if _, ok := logging.FieldsFromContext(c.Request.Context())["userId"]; !ok {
+			if userId, ok := utils.GetUserIdString(c); ok {
+				fields["userId"] = userId
+			}
 		}
 
 		// Add query parameters if present
@@ -61,8 +65,11 @@ func AccessLogMiddleware() gin.HandlerFunc {
 			fields["user_agent"] = userAgent
 		}
 
-		// Add request Id if present (useful for tracing)
-		if requestID := c.GetHeader("X-Request-Id"); requestID != "" {
+		// Add request Id if present (useful for tracing).  Prefer the one `RequestIdMiddleware`
+		// assigned, otherwise fall back to whatever the client sent.
+		if requestID, ok := utils.GetRequestId(c); ok {
+			fields["request_id"] = requestID
+		} else if requestID := c.GetHeader(RequestIdHeader); requestID != "" {
 			fields["request_id"] = requestID
 		}
 
@@ -71,12 +78,15 @@ func AccessLogMiddleware() gin.HandlerFunc {
 			fields["content_length"] = c.Request.ContentLength
 		}
 
+		// Cloud Logging's request object, only written by the formatter in GCP mode
+		fields[logging.HTTPRequestKey] = newHTTPRequest(c, statusCode, duration)
+
 		// Create the log message in the format: "<status> <method> <path>"
 		message := fmt.Sprintf("%d %s %s", statusCode, method, path)
 
 		// Log with appropriate level based on status code
 		logLevel := getLogLevel(statusCode)
-		logEntry := log.WithFields(fields)
+		logEntry := logging.FromContext(c.Request.Context()).WithFields(fields)
 
 		switch logLevel {
 		case log.ErrorLevel:
@@ -89,6 +99,26 @@ func AccessLogMiddleware() gin.HandlerFunc {
 	}
 }
 
+func newHTTPRequest(c *gin.Context, statusCode int, duration time.Duration) *logging.HTTPRequest {
+	httpRequest := &logging.HTTPRequest{
+		RequestMethod: c.Request.Method,
+		RequestUrl:    c.Request.URL.RequestURI(),
+		Status:        statusCode,
+		UserAgent:     c.Request.UserAgent(),
+		RemoteIp:      utils.GetClientIP(c),
+		Referer:       c.Request.Referer(),
+		Latency:       logging.FormatLatency(duration.Seconds()),
+		Protocol:      c.Request.Proto,
+	}
+	if c.Request.ContentLength > 0 {
+		httpRequest.RequestSize = strconv.FormatInt(c.Request.ContentLength, 10)
+	}
+	if size := c.Writer.Size(); size > 0 {
+		httpRequest.ResponseSize = strconv.Itoa(size)
+	}
+	return httpRequest
+}
+
 // getLogLevel determines the appropriate log level based on HTTP status code
 func getLogLevel(statusCode int) log.Level {
 	switch {
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Admiral-Piett/go-tools/gin/utils"
+	"github.com/Admiral-Piett/go-tools/logging"
+	"github.com/Admiral-Piett/go-tools/logging/testlog"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveAccessLog(path string, status int, headers map[string]string) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(AccessLogMiddleware())
+	router.GET("/temp", func(c *gin.Context) {
+		c.Status(status)
+	})
+	router.GET("/health", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", path, nil)
+	for k, v := range headers {
+		r.Header.Set(k, v)
+	}
+	router.ServeHTTP(w, r)
+}
+
+func TestAccessLogMiddleware_success(t *testing.T) {
+	logs := testlog.Capture(t)
+
+	serveAccessLog("/temp?page=2", http.StatusOK, map[string]string{
+		"User-Agent":    "test-agent",
+		RequestIdHeader: "req-1",
+	})
+
+	logs.AssertLogged(t, log.InfoLevel, "200 GET /temp", log.Fields{
+		"status_code": http.StatusOK,
+		"method":      "GET",
+		"path":        "/temp",
+		"query":       "page=2",
+		"user_agent":  "test-agent",
+		"request_id":  "req-1",
+	})
+	httpRequest, ok := logs.LastEntry().Fields[logging.HTTPRequestKey].(*logging.HTTPRequest)
+	assert.True(t, ok)
+	assert.Equal(t, "/temp?page=2", httpRequest.RequestUrl)
+}
+
+func TestAccessLogMiddleware_levelsByStatus(t *testing.T) {
+	logs := testlog.Capture(t)
+
+	serveAccessLog("/temp", http.StatusNotFound, nil)
+	serveAccessLog("/temp", http.StatusInternalServerError, nil)
+
+	logs.AssertLogged(t, log.WarnLevel, "404 GET /temp")
+	logs.AssertLogged(t, log.ErrorLevel, "500 GET /temp")
+}
+
+func TestAccessLogMiddleware_skipsHealth(t *testing.T) {
+	logs := testlog.Capture(t)
+
+	serveAccessLog("/health", http.StatusOK, nil)
+
+	assert.Empty(t, logs.Entries())
+}
+
+func TestAccessLogMiddleware_userId(t *testing.T) {
+	logs := testlog.Capture(t)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(AccessLogMiddleware())
+	router.GET("/temp", func(c *gin.Context) {
+		c.Request = c.Request.WithContext(utils.WithUserIdString(c.Request.Context(), "7"))
+	})
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/temp", nil))
+
+	logs.AssertLogged(t, log.InfoLevel, "200 GET /temp", log.Fields{"userId": "7"})
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Admiral-Piett/go-tools/gin/interfaces"
+	"github.com/Admiral-Piett/go-tools/gin/models"
+	"github.com/Admiral-Piett/go-tools/gin/utils"
+	"github.com/Admiral-Piett/go-tools/logging"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+type APIKeyMiddleware struct {
+	apiKeyService interfaces.APIKeyServiceInterface
+}
+
+func NewAPIKeyMiddleware(
+	apiKeyService interfaces.APIKeyServiceInterface,
+) *APIKeyMiddleware {
+	return &APIKeyMiddleware{
+		apiKeyService: apiKeyService,
+	}
+}
+
+// APIKeyAuth authenticates machine clients via the `X-API-Key` header and populates the same
+// request context values as `AuthMiddleware.RequireAuth`, so downstream handlers don't need to
+// care how the caller authenticated.  Any scopes passed must all be granted to the key.
+func (am *APIKeyMiddleware) APIKeyAuth(requiredScopes ...string) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		key, err := am.validateAPIKeyHeader(c.Request)
+		if err != nil {
+			logging.FromContext(c.Request.Context()).
+				WithError(err).
+				Warning("Validate API Key Header Failure")
+
+			c.AbortWithStatusJSON(
+				http.StatusUnauthorized,
+				models.ErrorResponses.UnauthorizedError,
+			)
+			return
+		}
+
+		if !key.HasScopes(requiredScopes...) {
+			logging.FromContext(c.Request.Context()).
+				WithField("api_key_prefix", key.Prefix).
+				Warning("API Key Missing Required Scopes")
+
+			c.AbortWithStatusJSON(
+				http.StatusForbidden,
+				models.ErrorResponses.ForbiddenError,
+			)
+			return
+		}
+
+		// Update request context
+		ctx := utils.WithUserIdString(c.Request.Context(), key.UserId)
+		ctx = utils.WithDeviceToken(ctx, "")
+		ctx = utils.WithAPIKeyPrefix(ctx, key.Prefix)
+		ctx = utils.WithScopes(ctx, key.ScopeList())
+		ctx = logging.WithFields(ctx, log.Fields{
+			"userId":         key.UserId,
+			"api_key_prefix": key.Prefix,
+		})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	})
+}
+
+func (am *APIKeyMiddleware) validateAPIKeyHeader(
+	r *http.Request,
+) (*models.APIKey, error) {
+	rawKey := r.Header.Get(APIKeyHeader)
+	if rawKey == "" {
+		return nil, errors.New("api key header missing")
+	}
+
+	return am.apiKeyService.ValidateAPIKey(rawKey)
+}
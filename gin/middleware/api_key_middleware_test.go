@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Admiral-Piett/go-tools/gin/mocks"
+	"github.com/Admiral-Piett/go-tools/gin/models"
+	"github.com/Admiral-Piett/go-tools/gin/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeyMiddleware_APIKeyAuth_success(t *testing.T) {
+	svc := &mocks.MockAPIKeyService{}
+	svc.MockValidateAPIKey = func(rawKey string) (*models.APIKey, error) {
+		return &models.APIKey{Prefix: "sk_abc", UserId: "7", Scopes: "read write"}, nil
+	}
+	h := APIKeyMiddleware{apiKeyService: svc}
+
+	var userId int
+	var found bool
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/temp", h.APIKeyAuth("read"), func(c *gin.Context) {
+		userId, found = utils.GetUserId(c)
+	})
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/temp", nil)
+	r.Header.Add(APIKeyHeader, "sk_abc_secret")
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []interface{}{"sk_abc_secret"}, svc.ValidateAPIKeyCalledWith)
+	assert.True(t, found)
+	assert.Equal(t, 7, userId)
+}
+
+func TestAPIKeyMiddleware_APIKeyAuth_missingHeader_401(t *testing.T) {
+	svc := &mocks.MockAPIKeyService{}
+	h := APIKeyMiddleware{apiKeyService: svc}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/temp", h.APIKeyAuth())
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/temp", nil)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Nil(t, svc.ValidateAPIKeyCalledWith)
+}
+
+func TestAPIKeyMiddleware_APIKeyAuth_invalidKey_401(t *testing.T) {
+	svc := &mocks.MockAPIKeyService{}
+	svc.MockValidateAPIKey = func(rawKey string) (*models.APIKey, error) {
+		return nil, errors.New("boom")
+	}
+	h := APIKeyMiddleware{apiKeyService: svc}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/temp", h.APIKeyAuth())
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/temp", nil)
+	r.Header.Add(APIKeyHeader, "garbage")
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAPIKeyMiddleware_APIKeyAuth_missingScope_403(t *testing.T) {
+	svc := &mocks.MockAPIKeyService{}
+	svc.MockValidateAPIKey = func(rawKey string) (*models.APIKey, error) {
+		return &models.APIKey{UserId: "1", Scopes: "read"}, nil
+	}
+	h := APIKeyMiddleware{apiKeyService: svc}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/temp", h.APIKeyAuth("read", "write"))
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/temp", nil)
+	r.Header.Add(APIKeyHeader, "sk_abc_secret")
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestAPIKeyMiddleware_APIKeyAuth_stringUserId_success(t *testing.T) {
+	svc := &mocks.MockAPIKeyService{}
+	svc.MockValidateAPIKey = func(rawKey string) (*models.APIKey, error) {
+		return &models.APIKey{UserId: "2b1c3f9e-5d4a-4e0b-9a57-6f1d2c3b4a5e"}, nil
+	}
+	h := APIKeyMiddleware{apiKeyService: svc}
+
+	var userId string
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/temp", h.APIKeyAuth(), func(c *gin.Context) {
+		userId, _ = utils.GetUserIdString(c)
+	})
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/temp", nil)
+	r.Header.Add(APIKeyHeader, "sk_abc_secret")
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2b1c3f9e-5d4a-4e0b-9a57-6f1d2c3b4a5e", userId)
+}
@@ -8,9 +8,10 @@ import (
 
 	"github.com/Admiral-Piett/go-tools/gin/interfaces"
 	"github.com/Admiral-Piett/go-tools/gin/models"
+	"github.com/Admiral-Piett/go-tools/gin/utils"
+	"github.com/Admiral-Piett/go-tools/logging"
 
 	"github.com/gin-gonic/gin"
-	log "github.com/sirupsen/logrus"
 )
 
 type AuthMiddleware struct {
@@ -23,11 +24,35 @@ func NewAuthMiddleware(tokenService interfaces.TokenServiceInterface) *AuthMiddl
 	}
 }
 
-func (am *AuthMiddleware) RequireAuth() gin.HandlerFunc {
+// RequireAuthOption changes which tokens RequireAuth accepts
+type RequireAuthOption func(*requireAuthConfig)
+
+type requireAuthConfig struct {
+	allowClients bool
+}
+
+// AllowClients also accepts client credentials tokens, which carry a client id and scopes but
+// no user, so handlers behind it can't count on `utils.GetUserId`.  Usually paired with
+// RequireScopes.
+func AllowClients() RequireAuthOption {
+	return func(cfg *requireAuthConfig) {
+		cfg.allowClients = true
+	}
+}
+
+// RequireAuth accepts user tokens only, unless AllowClients is passed
+func (am *AuthMiddleware) RequireAuth(opts ...RequireAuthOption) gin.HandlerFunc {
+	cfg := &requireAuthConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
 	return gin.HandlerFunc(func(c *gin.Context) {
-		ctx, err := am.validateAuthHeader(c.Request)
+		ctx, err := am.validateAuthHeader(c.Request, cfg.allowClients)
 		if err != nil {
-			log.WithError(err).Warning("Validate Auth Header Failure")
+			logging.FromContext(c.Request.Context()).
+				WithError(err).
+				Warning("Validate Auth Header Failure")
 
 			c.AbortWithStatusJSON(
 				http.StatusUnauthorized,
@@ -42,8 +67,28 @@ func (am *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 	})
 }
 
+// RequireScopes accepts only tokens granted all of the given scopes.  It must sit behind
+// `RequireAuth(AllowClients())`.  User tokens carry no scopes, so this effectively limits a
+// route to clients.
+func (am *AuthMiddleware) RequireScopes(scopes ...string) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		granted, _ := utils.GetScopes(c)
+		if !models.HasScopes(granted, scopes...) {
+			logging.FromContext(c.Request.Context()).Warning("Require Scopes Failure - Missing Scope")
+
+			c.AbortWithStatusJSON(
+				http.StatusForbidden,
+				models.ErrorResponses.ForbiddenError,
+			)
+			return
+		}
+		c.Next()
+	})
+}
+
 func (am *AuthMiddleware) validateAuthHeader(
 	r *http.Request,
+	allowClients bool,
 ) (context.Context, error) {
 	authHeader := r.Header.Get("Authorization")
 	if authHeader == "" {
@@ -64,15 +109,31 @@ func (am *AuthMiddleware) validateAuthHeader(
 		return r.Context(), err
 	}
 
-	// Decrypt user ID
-	userId, err := am.tokenService.DecryptUserID(claims.EncryptedUserID)
+	// Client credentials tokens carry a client rather than a user
+	if claims.ClientId != "" && claims.EncryptedUserID == "" {
+		if !allowClients {
+			return r.Context(), errors.New("client token on a user only route")
+		}
+		ctx := utils.WithClientId(r.Context(), claims.ClientId)
+		ctx = utils.WithScopes(ctx, models.ParseScopes(claims.Scope))
+		ctx = utils.WithClaims(ctx, claims)
+		ctx = logging.WithField(ctx, "client_id", claims.ClientId)
+		return ctx, nil
+	}
+
+	// Decrypt user ID - kept as a string so any primary key type works
+	userId, err := utils.DecryptUserIdString(am.tokenService, claims.EncryptedUserID)
 	if err != nil {
 		return r.Context(), err
 	}
 
 	// Add user context
-	ctx := context.WithValue(r.Context(), "userId", userId)
-	ctx = context.WithValue(ctx, "deviceToken", claims.DeviceToken)
+	ctx := utils.WithUserIdString(r.Context(), userId)
+	ctx = utils.WithDeviceToken(ctx, claims.DeviceToken)
+	ctx = utils.WithClaims(ctx, claims)
+	// Deprecated bare keys, for app code that hasn't moved to the accessors yet
+	ctx = utils.WithLegacyAuthValues(ctx, userId, claims.DeviceToken)
+	ctx = logging.WithField(ctx, "userId", userId)
 
 	return ctx, nil
 }
@@ -7,7 +7,10 @@ import (
 	"testing"
 
 	"github.com/Admiral-Piett/go-tools/gin/models"
+	"github.com/Admiral-Piett/go-tools/gin/utils"
+	"github.com/Admiral-Piett/go-tools/logging"
 
+	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/gin-gonic/gin"
@@ -20,14 +23,27 @@ func TestAuthMiddleware_RequireAuth_success(t *testing.T) {
 	tok.MockValidateAccessToken = func(tokenString string) (*models.AuthClaims, error) {
 		r := &models.AuthClaims{
 			EncryptedUserID: "encrypted-user-id",
+			DeviceToken:     "device-token",
 		}
 		return r, nil
 	}
+	tok.MockDecryptUserID = func(encryptedUserID string) (int, error) {
+		return 7, nil
+	}
 	h := AuthMiddleware{tokenService: tok}
 
+	var userId int
+	var deviceToken string
+	var claims *models.AuthClaims
+	var logFields log.Fields
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
-	router.POST("/temp", h.RequireAuth())
+	router.POST("/temp", h.RequireAuth(), func(c *gin.Context) {
+		userId, _ = utils.GetUserId(c)
+		deviceToken, _ = utils.GetDeviceToken(c)
+		claims, _ = utils.GetClaims(c)
+		logFields = logging.FieldsFromContext(c.Request.Context())
+	})
 	w := httptest.NewRecorder()
 	r := httptest.NewRequest("POST", "/temp", nil)
 	r.Header.Add("Authorization", "Bearer valid-token")
@@ -45,6 +61,37 @@ func TestAuthMiddleware_RequireAuth_success(t *testing.T) {
 		[]interface{}{"encrypted-user-id"},
 		tok.DecryptUserIDCalledWith,
 	)
+	assert.Equal(t, 7, userId)
+	assert.Equal(t, "device-token", deviceToken)
+	assert.Equal(t, "encrypted-user-id", claims.EncryptedUserID)
+	assert.Equal(t, "7", logFields["userId"])
+}
+
+func TestAuthMiddleware_RequireAuth_legacyStringKeys_success(t *testing.T) {
+	tok := &mocks.MockTokenService{}
+	tok.MockValidateAccessToken = func(tokenString string) (*models.AuthClaims, error) {
+		return &models.AuthClaims{EncryptedUserID: "encrypted-user-id", DeviceToken: "device-token"}, nil
+	}
+	tok.MockDecryptUserIDString = func(encryptedUserID string) (string, error) {
+		return "7", nil
+	}
+	h := AuthMiddleware{tokenService: tok}
+
+	var userId, deviceToken interface{}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/temp", h.RequireAuth(), func(c *gin.Context) {
+		userId = c.Request.Context().Value("userId")
+		deviceToken = c.Request.Context().Value("deviceToken")
+	})
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/temp", nil)
+	r.Header.Add("Authorization", "Bearer valid-token")
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 7, userId)
+	assert.Equal(t, "device-token", deviceToken)
 }
 
 func TestAuthMiddleware_RequireAuth_missingAuthHeader_401(t *testing.T) {
@@ -137,3 +184,122 @@ func TestAuthMiddleware_RequireAuth_unableToDecryptUserId_401(t *testing.T) {
 
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 }
+
+func TestAuthMiddleware_RequireAuth_stringUserId_success(t *testing.T) {
+	tok := &mocks.MockTokenService{}
+	tok.MockDecryptUserIDString = func(encryptedUserID string) (string, error) {
+		return "2b1c3f9e-5d4a-4e0b-9a57-6f1d2c3b4a5e", nil
+	}
+	h := AuthMiddleware{tokenService: tok}
+
+	var userId string
+	var intFound bool
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/temp", h.RequireAuth(), func(c *gin.Context) {
+		userId, _ = utils.GetUserIdString(c)
+		_, intFound = utils.GetUserId(c)
+	})
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/temp", nil)
+	r.Header.Add("Authorization", "Bearer valid-token")
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2b1c3f9e-5d4a-4e0b-9a57-6f1d2c3b4a5e", userId)
+	assert.False(t, intFound)
+}
+
+func TestAuthMiddleware_RequireAuth_clientToken_success(t *testing.T) {
+	tok := &mocks.MockTokenService{}
+	tok.MockValidateAccessToken = func(tokenString string) (*models.AuthClaims, error) {
+		r := &models.AuthClaims{
+			ClientId: "client-id",
+			Scope:    "jobs:read jobs:write",
+		}
+		return r, nil
+	}
+	h := AuthMiddleware{tokenService: tok}
+
+	var clientId string
+	var scopes []string
+	var hasUser bool
+	var logFields log.Fields
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/temp", h.RequireAuth(AllowClients()), func(c *gin.Context) {
+		clientId, _ = utils.GetClientId(c)
+		scopes, _ = utils.GetScopes(c)
+		_, hasUser = utils.GetUserIdString(c)
+		logFields = logging.FieldsFromContext(c.Request.Context())
+	})
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/temp", nil)
+	r.Header.Add("Authorization", "Bearer valid-token")
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, tok.DecryptUserIDStringCalledWith)
+	assert.Equal(t, "client-id", clientId)
+	assert.Equal(t, []string{"jobs:read", "jobs:write"}, scopes)
+	assert.False(t, hasUser)
+	assert.Equal(t, "client-id", logFields["client_id"])
+}
+
+func TestAuthMiddleware_RequireAuth_clientTokenNotAllowed_401(t *testing.T) {
+	tok := &mocks.MockTokenService{}
+	tok.MockValidateAccessToken = func(tokenString string) (*models.AuthClaims, error) {
+		return &models.AuthClaims{ClientId: "client-id", Scope: "jobs:read"}, nil
+	}
+	h := AuthMiddleware{tokenService: tok}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/temp", h.RequireAuth(), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/temp", nil)
+	r.Header.Add("Authorization", "Bearer valid-token")
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAuthMiddleware_RequireScopes_success(t *testing.T) {
+	h := AuthMiddleware{tokenService: &mocks.MockTokenService{}}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/temp", func(c *gin.Context) {
+		c.Request = c.Request.WithContext(
+			utils.WithScopes(c.Request.Context(), []string{"jobs:read", "jobs:write"}),
+		)
+	}, h.RequireScopes("jobs:write"), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/temp", nil)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestAuthMiddleware_RequireScopes_missingScope_403(t *testing.T) {
+	h := AuthMiddleware{tokenService: &mocks.MockTokenService{}}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/temp", func(c *gin.Context) {
+		c.Request = c.Request.WithContext(
+			utils.WithScopes(c.Request.Context(), []string{"jobs:read"}),
+		)
+	}, h.RequireScopes("jobs:write"), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/temp", nil)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"github.com/Admiral-Piett/go-tools/gin/utils"
+	"github.com/Admiral-Piett/go-tools/settings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClientIPMiddleware resolves the real client address once per request, for the access log,
+// rate limiter and handlers to read with `utils.GetClientIP`.  Install it before those:
+//
+//	clientIP, err := middleware.NewClientIPMiddleware(cfg)
+//	router.Use(clientIP.ResolveClientIP(), middleware.AccessLogMiddleware())
+type ClientIPMiddleware struct {
+	resolver *utils.ClientIPResolver
+}
+
+func NewClientIPMiddleware(cfg *settings.BaseSettings) (*ClientIPMiddleware, error) {
+	resolver, err := utils.NewClientIPResolver(
+		cfg.TrustedProxiesSlice,
+		cfg.TrustedProxyHops,
+		cfg.ClientIpHeader,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &ClientIPMiddleware{
+		resolver: resolver,
+	}, nil
+}
+
+func (cm *ClientIPMiddleware) ResolveClientIP() gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		clientIP := cm.resolver.Resolve(c.Request)
+		c.Request = c.Request.WithContext(utils.WithClientIP(c.Request.Context(), clientIP))
+		c.Next()
+	})
+}
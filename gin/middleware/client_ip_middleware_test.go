@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Admiral-Piett/go-tools/gin/utils"
+	"github.com/Admiral-Piett/go-tools/settings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewClientIPMiddleware_invalidProxy_error(t *testing.T) {
+	cfg := &settings.BaseSettings{TrustedProxiesSlice: []string{"nope"}}
+
+	_, err := NewClientIPMiddleware(cfg)
+
+	assert.Error(t, err)
+}
+
+func TestClientIPMiddleware_ResolveClientIP_success(t *testing.T) {
+	cfg := &settings.BaseSettings{TrustedProxyHops: 1, ClientIpHeader: "X-Forwarded-For"}
+	h, err := NewClientIPMiddleware(cfg)
+	assert.Nil(t, err)
+
+	var clientIP, rateLimitKey string
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/temp", h.ResolveClientIP(), func(c *gin.Context) {
+		clientIP = utils.GetClientIP(c)
+		rateLimitKey = KeyByClientIP(c)
+	})
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/temp", nil)
+	r.RemoteAddr = "169.254.1.1:1234"
+	r.Header.Set("X-Forwarded-For", "1.1.1.1, 198.51.100.1")
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "198.51.100.1", clientIP)
+	assert.Equal(t, "ip:198.51.100.1", rateLimitKey)
+}
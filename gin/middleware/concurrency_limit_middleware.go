@@ -0,0 +1,195 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Admiral-Piett/go-tools/gin/models"
+	"github.com/Admiral-Piett/go-tools/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConcurrencyLimitMiddleware caps how many requests are processed at once, across the instance
+// and per route, so one slow endpoint can't tie up every worker.  Requests over the cap wait up
+// to queueTimeout for a slot, and are shed with a 503 if the queue is full or the wait runs out.
+//
+//	concurrency := middleware.NewConcurrencyLimitMiddleware(100, 20, 50, 2*time.Second)
+//	router.Use(concurrency.Limit())
+//	handlers.RegisterHealthDetail("concurrency", concurrency.HealthDetail)
+type ConcurrencyLimitMiddleware struct {
+	// global is nil when there's no overall cap
+	global       *concurrencyLimiter
+	perRoute     int
+	maxQueue     int
+	queueTimeout time.Duration
+
+	mu     sync.Mutex
+	routes map[string]*concurrencyLimiter
+}
+
+// NewConcurrencyLimitMiddleware caps in flight requests at maxInFlight overall (0 for no overall
+// cap) and maxInFlightPerRoute for each route (0 for no per route cap).  Each cap queues up to
+// maxQueue requests.
+func NewConcurrencyLimitMiddleware(
+	maxInFlight, maxInFlightPerRoute, maxQueue int,
+	queueTimeout time.Duration,
+) *ConcurrencyLimitMiddleware {
+	maxQueue = max(maxQueue, 0)
+	cm := &ConcurrencyLimitMiddleware{
+		perRoute:     maxInFlightPerRoute,
+		maxQueue:     maxQueue,
+		queueTimeout: queueTimeout,
+		routes:       map[string]*concurrencyLimiter{},
+	}
+	if maxInFlight > 0 {
+		cm.global = newConcurrencyLimiter(maxInFlight, maxQueue)
+	}
+	return cm
+}
+
+func (cm *ConcurrencyLimitMiddleware) Limit() gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		// Take the route slot first, so requests queued for a slow route don't hold global slots
+		route := cm.routeLimiter(c)
+		if route != nil {
+			if !route.acquire(c.Request.Context(), cm.queueTimeout) {
+				cm.shed(c, "route")
+				return
+			}
+			defer route.release()
+		}
+
+		if cm.global != nil {
+			if !cm.global.acquire(c.Request.Context(), cm.queueTimeout) {
+				cm.shed(c, "global")
+				return
+			}
+			defer cm.global.release()
+		}
+
+		c.Next()
+	})
+}
+
+// ConcurrencyStats is a snapshot of one cap's load
+type ConcurrencyStats struct {
+	InFlight    int    `json:"in_flight"`
+	Queued      int64  `json:"queued"`
+	MaxInFlight int    `json:"max_in_flight"`
+	MaxQueue    int64  `json:"max_queue"`
+	Rejected    uint64 `json:"rejected"`
+}
+
+type ConcurrencyHealth struct {
+	// ConcurrencyStats is left zero when there's no overall cap
+	ConcurrencyStats
+	Routes map[string]ConcurrencyStats `json:"routes,omitempty"`
+}
+
+// HealthDetail reports current load, for `handlers.RegisterHealthDetail`
+func (cm *ConcurrencyLimitMiddleware) HealthDetail() interface{} {
+	health := ConcurrencyHealth{}
+	if cm.global != nil {
+		health.ConcurrencyStats = cm.global.stats()
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	if len(cm.routes) > 0 {
+		health.Routes = make(map[string]ConcurrencyStats, len(cm.routes))
+		for name, limiter := range cm.routes {
+			health.Routes[name] = limiter.stats()
+		}
+	}
+	return health
+}
+
+func (cm *ConcurrencyLimitMiddleware) routeLimiter(c *gin.Context) *concurrencyLimiter {
+	if cm.perRoute <= 0 || c.FullPath() == "" {
+		return nil
+	}
+	name := c.Request.Method + " " + c.FullPath()
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	limiter, ok := cm.routes[name]
+	if !ok {
+		limiter = newConcurrencyLimiter(cm.perRoute, cm.maxQueue)
+		cm.routes[name] = limiter
+	}
+	return limiter
+}
+
+func (cm *ConcurrencyLimitMiddleware) shed(c *gin.Context, limit string) {
+	logging.FromContext(c.Request.Context()).
+		WithField("limit", limit).
+		Warning("Concurrency Limit Reached - Request Shed")
+
+	// By the time a queued request gives up, a slot has likely freed, so retry after about as
+	// long as we were willing to wait
+	c.Header("Retry-After", strconv.FormatInt(max(ceilSeconds(cm.queueTimeout), 1), 10))
+	c.AbortWithStatusJSON(
+		http.StatusServiceUnavailable,
+		models.ErrorResponses.ServiceUnavailableError,
+	)
+}
+
+// concurrencyLimiter is a semaphore with a bounded wait queue
+type concurrencyLimiter struct {
+	slots    chan struct{}
+	maxQueue int64
+	queued   atomic.Int64
+	rejected atomic.Uint64
+}
+
+func newConcurrencyLimiter(maxInFlight, maxQueue int) *concurrencyLimiter {
+	return &concurrencyLimiter{
+		slots:    make(chan struct{}, maxInFlight),
+		maxQueue: int64(maxQueue),
+	}
+}
+
+func (l *concurrencyLimiter) acquire(ctx context.Context, timeout time.Duration) bool {
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	default:
+	}
+
+	if l.queued.Add(1) > l.maxQueue {
+		l.queued.Add(-1)
+		l.rejected.Add(1)
+		return false
+	}
+	defer l.queued.Add(-1)
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	l.rejected.Add(1)
+	return false
+}
+
+func (l *concurrencyLimiter) release() {
+	<-l.slots
+}
+
+func (l *concurrencyLimiter) stats() ConcurrencyStats {
+	return ConcurrencyStats{
+		InFlight:    len(l.slots),
+		Queued:      l.queued.Load(),
+		MaxInFlight: cap(l.slots),
+		MaxQueue:    l.maxQueue,
+		Rejected:    l.rejected.Load(),
+	}
+}
@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// blockingRouter serves /slow and /fast through h, /slow holding its slot until release closes
+func blockingRouter(h *ConcurrencyLimitMiddleware, started chan struct{}, release chan struct{}) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(h.Limit())
+	router.GET("/slow", func(c *gin.Context) {
+		started <- struct{}{}
+		<-release
+	})
+	router.GET("/fast", func(c *gin.Context) {})
+	return router
+}
+
+func serveAsync(router *gin.Engine, path string, wg *sync.WaitGroup) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+	}()
+	return w
+}
+
+func TestConcurrencyLimitMiddleware_Limit_success(t *testing.T) {
+	h := NewConcurrencyLimitMiddleware(1, 0, 0, time.Second)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/temp", h.Limit())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/temp", nil))
+	w2 := httptest.NewRecorder()
+	router.ServeHTTP(w2, httptest.NewRequest("GET", "/temp", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusOK, w2.Code)
+	assert.Equal(t, 0, h.global.stats().InFlight)
+}
+
+func TestConcurrencyLimitMiddleware_Limit_queueFull_503(t *testing.T) {
+	h := NewConcurrencyLimitMiddleware(1, 0, 0, time.Second)
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	router := blockingRouter(h, started, release)
+	wg := &sync.WaitGroup{}
+
+	slow := serveAsync(router, "/slow", wg)
+	<-started
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/fast", nil))
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, http.StatusOK, slow.Code)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "SERVICE_UNAVAILABLE")
+	assert.Equal(t, uint64(1), h.global.stats().Rejected)
+}
+
+func TestConcurrencyLimitMiddleware_Limit_queueTimeout_503(t *testing.T) {
+	h := NewConcurrencyLimitMiddleware(1, 0, 1, 10*time.Millisecond)
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	router := blockingRouter(h, started, release)
+	wg := &sync.WaitGroup{}
+
+	serveAsync(router, "/slow", wg)
+	<-started
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/fast", nil))
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, int64(0), h.global.stats().Queued)
+}
+
+func TestConcurrencyLimitMiddleware_Limit_queuedThenServed(t *testing.T) {
+	h := NewConcurrencyLimitMiddleware(1, 0, 1, time.Second)
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	router := blockingRouter(h, started, release)
+	wg := &sync.WaitGroup{}
+
+	serveAsync(router, "/slow", wg)
+	<-started
+	fast := serveAsync(router, "/fast", wg)
+	assert.Eventually(t, func() bool {
+		return h.global.stats().Queued == 1
+	}, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, http.StatusOK, fast.Code)
+}
+
+func TestConcurrencyLimitMiddleware_Limit_perRoute_otherRoutesUnaffected(t *testing.T) {
+	h := NewConcurrencyLimitMiddleware(10, 1, 0, time.Second)
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	router := blockingRouter(h, started, release)
+	wg := &sync.WaitGroup{}
+
+	serveAsync(router, "/slow", wg)
+	<-started
+	secondSlow := httptest.NewRecorder()
+	router.ServeHTTP(secondSlow, httptest.NewRequest("GET", "/slow", nil))
+	fast := httptest.NewRecorder()
+	router.ServeHTTP(fast, httptest.NewRequest("GET", "/fast", nil))
+
+	health := h.HealthDetail().(ConcurrencyHealth)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, http.StatusServiceUnavailable, secondSlow.Code)
+	assert.Equal(t, http.StatusOK, fast.Code)
+	assert.Equal(t, 1, health.InFlight)
+	assert.Equal(t, 1, health.Routes["GET /slow"].InFlight)
+	assert.Equal(t, uint64(1), health.Routes["GET /slow"].Rejected)
+	assert.Equal(t, 0, health.Routes["GET /fast"].InFlight)
+}
+
+func TestConcurrencyLimiter_acquire_contextCancelled(t *testing.T) {
+	l := newConcurrencyLimiter(1, 1)
+	l.acquire(context.Background(), time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result := l.acquire(ctx, time.Second)
+
+	assert.False(t, result)
+	assert.Equal(t, int64(0), l.queued.Load())
+}
+
+func TestConcurrencyLimitMiddleware_Limit_noGlobalCap(t *testing.T) {
+	h := NewConcurrencyLimitMiddleware(0, 1, -1, time.Second)
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	router := blockingRouter(h, started, release)
+	wg := &sync.WaitGroup{}
+
+	slow := serveAsync(router, "/slow", wg)
+	<-started
+	fast := httptest.NewRecorder()
+	router.ServeHTTP(fast, httptest.NewRequest("GET", "/fast", nil))
+	secondSlow := httptest.NewRecorder()
+	router.ServeHTTP(secondSlow, httptest.NewRequest("GET", "/slow", nil))
+	close(release)
+	wg.Wait()
+
+	assert.Nil(t, h.global)
+	assert.Equal(t, http.StatusOK, slow.Code)
+	assert.Equal(t, http.StatusOK, fast.Code)
+	assert.Equal(t, http.StatusServiceUnavailable, secondSlow.Code)
+	assert.Zero(t, h.HealthDetail().(ConcurrencyHealth).MaxInFlight)
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/Admiral-Piett/go-tools/gin/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// KeyFunc picks the bucket a request is counted against.  Returning "" means the limiter
+// doesn't apply to this request (e.g. `KeyByUserId` on an anonymous request), so it's let
+// through uncounted - stack another limiter to cover those.
+type KeyFunc func(c *gin.Context) string
+
+// KeyByClientIP is the default, everyone behind the same address shares a bucket.  Install
+// `ClientIPMiddleware` first when running behind a load balancer.
+func KeyByClientIP(c *gin.Context) string {
+	return "ip:" + utils.GetClientIP(c)
+}
+
+// KeyByUserId buckets authenticated users individually, whatever address they come from.  It
+// must sit behind `AuthMiddleware.RequireAuth`.
+func KeyByUserId(c *gin.Context) string {
+	userId, ok := utils.GetUserIdString(c)
+	if !ok || userId == "" {
+		return ""
+	}
+	return "user:" + userId
+}
+
+// KeyByAPIKey buckets each API key separately.  It must sit behind `APIKeyMiddleware.APIKeyAuth`.
+func KeyByAPIKey(c *gin.Context) string {
+	prefix, ok := utils.GetAPIKeyPrefix(c)
+	if !ok || prefix == "" {
+		return ""
+	}
+	return "apikey:" + prefix
+}
+
+// KeyByRoute buckets on the route template (e.g. `/users/:id`), not the raw path, so one
+// bucket covers every id.  On its own it's a global limit for the route.
+func KeyByRoute(c *gin.Context) string {
+	route := c.FullPath()
+	if route == "" {
+		return ""
+	}
+	return "route:" + c.Request.Method + " " + route
+}
+
+// CombineKeys buckets on every key together, e.g. `CombineKeys(KeyByUserId, KeyByRoute)` for
+// a per user, per route limit.  If any key is empty the combination is too.
+func CombineKeys(fns ...KeyFunc) KeyFunc {
+	return func(c *gin.Context) string {
+		parts := make([]string, 0, len(fns))
+		for _, fn := range fns {
+			key := fn(c)
+			if key == "" {
+				return ""
+			}
+			parts = append(parts, key)
+		}
+		return strings.Join(parts, "|")
+	}
+}
+
+// FirstKey uses the first non-empty key, e.g. `FirstKey(KeyByUserId, KeyByClientIP)` to
+// limit users individually and fall back to their address when they're anonymous.
+func FirstKey(fns ...KeyFunc) KeyFunc {
+	return func(c *gin.Context) string {
+		for _, fn := range fns {
+			if key := fn(c); key != "" {
+				return key
+			}
+		}
+		return ""
+	}
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Admiral-Piett/go-tools/gin/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// serveKey runs fn on a request to /users/7, after setup has populated the request context
+func serveKey(fn KeyFunc, setup func(c *gin.Context)) string {
+	var key string
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/users/:id", func(c *gin.Context) {
+		if setup != nil {
+			setup(c)
+		}
+		key = fn(c)
+	})
+	r := httptest.NewRequest("GET", "/users/7", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	return key
+}
+
+func setUserId(c *gin.Context) {
+	c.Request = c.Request.WithContext(utils.WithUserIdString(c.Request.Context(), "42"))
+}
+
+func TestKeyByClientIP(t *testing.T) {
+	assert.Equal(t, "ip:10.0.0.1", serveKey(KeyByClientIP, nil))
+}
+
+func TestKeyByUserId(t *testing.T) {
+	assert.Equal(t, "user:42", serveKey(KeyByUserId, setUserId))
+	assert.Equal(t, "", serveKey(KeyByUserId, nil))
+}
+
+func TestKeyByAPIKey(t *testing.T) {
+	setPrefix := func(c *gin.Context) {
+		c.Request = c.Request.WithContext(utils.WithAPIKeyPrefix(c.Request.Context(), "sk_abc"))
+	}
+	assert.Equal(t, "apikey:sk_abc", serveKey(KeyByAPIKey, setPrefix))
+	assert.Equal(t, "", serveKey(KeyByAPIKey, nil))
+}
+
+func TestKeyByRoute(t *testing.T) {
+	assert.Equal(t, "route:GET /users/:id", serveKey(KeyByRoute, nil))
+}
+
+func TestCombineKeys(t *testing.T) {
+	fn := CombineKeys(KeyByUserId, KeyByRoute)
+
+	assert.Equal(t, "user:42|route:GET /users/:id", serveKey(fn, setUserId))
+	assert.Equal(t, "", serveKey(fn, nil))
+}
+
+func TestFirstKey(t *testing.T) {
+	fn := FirstKey(KeyByUserId, KeyByClientIP)
+
+	assert.Equal(t, "user:42", serveKey(fn, setUserId))
+	assert.Equal(t, "ip:10.0.0.1", serveKey(fn, nil))
+}
@@ -3,6 +3,7 @@ package middleware
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/Admiral-Piett/go-tools/gin/models"
@@ -12,32 +13,109 @@ import (
 	"github.com/ulule/limiter/v3/drivers/store/memory"
 )
 
+// RateLimitMiddleware limits requests per key.  By default it counts them in fixed windows,
+// `WithRateLimiter` swaps in a token bucket or sliding window instead.  Several can be stacked on
+// one route group with different rates and keys, e.g. a loose per address limit plus a tight per
+// user one:
+//
+//	perIp := middleware.NewRateLimitMiddleware(300, time.Minute)
+//	perUser := middleware.NewRateLimitMiddleware(60, time.Minute,
+//		middleware.WithKeyFunc(middleware.KeyByUserId),
+//		middleware.WithName("user"),
+//	)
+//	api := router.Group("/api", perIp.Limit(), authMiddleware.RequireAuth(), perUser.Limit())
 type RateLimitMiddleware struct {
-	limiter *limiter.Limiter
+	limiter     *limiter.Limiter
+	store       limiter.Store
+	rateLimiter RateLimiter
+	keyFunc     KeyFunc
+	name        string
+	tierFunc    TierFunc
+	tiers       map[string]RateLimiter
+}
+
+type RateLimitOption func(*RateLimitMiddleware)
+
+// WithKeyFunc changes what requests are bucketed on, the default is `KeyByClientIP`
+func WithKeyFunc(fn KeyFunc) RateLimitOption {
+	return func(rlm *RateLimitMiddleware) {
+		rlm.keyFunc = fn
+	}
+}
+
+// WithStore swaps the default in memory store, e.g. for `services.NewRateLimitStore` so
+// every instance of an app shares the same limits.  Any `limiter.Store` works, including
+// ulule's Redis driver.  The store only backs the default fixed window, limiters passed to
+// `WithRateLimiter` or `WithTiers` keep their own state in memory, per instance.
+func WithStore(store limiter.Store) RateLimitOption {
+	return func(rlm *RateLimitMiddleware) {
+		rlm.store = store
+	}
+}
+
+// WithRateLimiter replaces the fixed window with another algorithm, e.g.
+// `NewTokenBucketLimiter(20, 60, time.Minute)`.  The limit and window passed to
+// `NewRateLimitMiddleware` are then unused, and so is any `WithStore`.
+func WithRateLimiter(rateLimiter RateLimiter) RateLimitOption {
+	return func(rlm *RateLimitMiddleware) {
+		rlm.rateLimiter = rateLimiter
+	}
+}
+
+// WithTiers gives some callers a different limit, chosen per request by tierFunc.  Callers
+// outside every tier get the default limit.  Tier limiters don't use `WithStore`, their state is
+// per instance.
+func WithTiers(tierFunc TierFunc, tiers map[string]RateLimiter) RateLimitOption {
+	return func(rlm *RateLimitMiddleware) {
+		rlm.tierFunc = tierFunc
+		rlm.tiers = tiers
+	}
+}
+
+// WithName namespaces this limiter's keys, so limiters sharing a store don't share buckets
+func WithName(name string) RateLimitOption {
+	return func(rlm *RateLimitMiddleware) {
+		rlm.name = name
+	}
 }
 
 func NewRateLimitMiddleware(
 	limit int,
 	window time.Duration,
+	opts ...RateLimitOption,
 ) *RateLimitMiddleware {
-	store := memory.NewStore()
 	rate := limiter.Rate{
 		Period: window,
 		Limit:  int64(limit),
 	}
 
-	return &RateLimitMiddleware{
-		limiter: limiter.New(store, rate),
+	rlm := &RateLimitMiddleware{
+		keyFunc: KeyByClientIP,
+	}
+	for _, opt := range opts {
+		opt(rlm)
 	}
+	if rlm.store == nil {
+		rlm.store = memory.NewStore()
+	}
+	rlm.limiter = limiter.New(rlm.store, rate)
+	return rlm
 }
 
 func (rlm *RateLimitMiddleware) Limit() gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
-		// Use client IP as the key for rate limiting
-		// TODO - test w/ gcloud headers as we might not get the real client IP anymore?
-		key := c.ClientIP()
+		key := rlm.key(c)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		rateLimiter, tier := rlm.rateLimiterFor(c)
+		if tier != "" {
+			key = "tier:" + tier + ":" + key
+		}
 
-		context, err := rlm.limiter.Get(c, key)
+		result, err := rateLimiter.Allow(c, key)
 		if err != nil {
 			c.AbortWithStatusJSON(
 				http.StatusInternalServerError,
@@ -46,15 +124,24 @@ func (rlm *RateLimitMiddleware) Limit() gin.HandlerFunc {
 			return
 		}
 
-		// Add rate limit headers
-		c.Header("X-RateLimit-Limit", fmt.Sprintf("%d", context.Limit))
-		c.Header("X-RateLimit-Remaining", fmt.Sprintf("%d", context.Remaining))
-		c.Header("X-RateLimit-Reset", fmt.Sprintf("%d", context.Reset))
+		// Add rate limit headers.  With stacked limiters report whichever is closest to its limit.
+		if !moreRestrictiveLimitReported(c, result.Remaining) {
+			resetAfter := ceilSeconds(result.ResetAfter)
+			c.Header("RateLimit-Limit", fmt.Sprintf("%d", result.Limit))
+			c.Header("RateLimit-Remaining", fmt.Sprintf("%d", result.Remaining))
+			c.Header("RateLimit-Reset", fmt.Sprintf("%d", resetAfter))
+			// Legacy headers, X-RateLimit-Reset is a unix timestamp rather than seconds from now
+			c.Header("X-RateLimit-Limit", fmt.Sprintf("%d", result.Limit))
+			c.Header("X-RateLimit-Remaining", fmt.Sprintf("%d", result.Remaining))
+			c.Header("X-RateLimit-Reset", fmt.Sprintf("%d", time.Now().Unix()+resetAfter))
+		}
 
-		if context.Reached {
+		if !result.Allowed {
+			retryAfter := ceilSeconds(result.RetryAfter)
+			c.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
 			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
 				"error":       "Too many requests",
-				"retry_after": context.Reset - time.Now().Unix(),
+				"retry_after": retryAfter,
 			})
 			return
 		}
@@ -62,3 +149,45 @@ func (rlm *RateLimitMiddleware) Limit() gin.HandlerFunc {
 		c.Next()
 	})
 }
+
+// rateLimiterFor returns the limiter for the caller's tier, if they're in one
+func (rlm *RateLimitMiddleware) rateLimiterFor(c *gin.Context) (RateLimiter, string) {
+	if rlm.tierFunc != nil {
+		tier := rlm.tierFunc(c)
+		if rateLimiter, ok := rlm.tiers[tier]; ok && tier != "" {
+			return rateLimiter, tier
+		}
+	}
+	if rlm.rateLimiter != nil {
+		return rlm.rateLimiter, ""
+	}
+	return &fixedWindowLimiter{limiter: rlm.limiter}, ""
+}
+
+func (rlm *RateLimitMiddleware) key(c *gin.Context) string {
+	keyFunc := rlm.keyFunc
+	if keyFunc == nil {
+		keyFunc = KeyByClientIP
+	}
+	key := keyFunc(c)
+	if key == "" || rlm.name == "" {
+		return key
+	}
+	return rlm.name + ":" + key
+}
+
+func moreRestrictiveLimitReported(c *gin.Context, remaining int64) bool {
+	reported, err := strconv.ParseInt(c.Writer.Header().Get("RateLimit-Remaining"), 10, 64)
+	if err != nil {
+		return false
+	}
+	return reported < remaining
+}
+
+// ceilSeconds rounds up, so clients never retry a moment too early
+func ceilSeconds(d time.Duration) int64 {
+	if d <= 0 {
+		return 0
+	}
+	return int64((d + time.Second - 1) / time.Second)
+}
@@ -8,6 +8,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Admiral-Piett/go-tools/gin/utils"
+
 	"github.com/stretchr/testify/assert"
 
 	"github.com/ulule/limiter/v3"
@@ -121,3 +123,172 @@ func TestRateLimitMiddleware_Limit_limitReached_429(t *testing.T) {
 
 	assert.Equal(t, http.StatusTooManyRequests, w.Code)
 }
+
+func TestNewRateLimitMiddleware_withOptions(t *testing.T) {
+	result := NewRateLimitMiddleware(
+		3,
+		time.Minute,
+		WithKeyFunc(KeyByUserId),
+		WithName("user"),
+	)
+
+	assert.NotNil(t, result.keyFunc)
+	assert.Equal(t, "user", result.name)
+}
+
+func TestRateLimitMiddleware_Limit_emptyKey_skipped(t *testing.T) {
+	h := NewRateLimitMiddleware(1, time.Minute, WithKeyFunc(KeyByUserId))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/temp", h.Limit())
+
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/temp", nil)
+		router.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("X-RateLimit-Limit"))
+	}
+}
+
+func TestRateLimitMiddleware_Limit_perUser_separateBuckets(t *testing.T) {
+	h := NewRateLimitMiddleware(1, time.Minute, WithKeyFunc(KeyByUserId))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/temp", func(c *gin.Context) {
+		c.Request = c.Request.WithContext(
+			utils.WithUserIdString(c.Request.Context(), c.GetHeader("X-User")),
+		)
+	}, h.Limit())
+
+	serve := func(user string) int {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/temp", nil)
+		r.Header.Set("X-User", user)
+		router.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("1"))
+	assert.Equal(t, http.StatusOK, serve("2"))
+	assert.Equal(t, http.StatusTooManyRequests, serve("1"))
+}
+
+func TestRateLimitMiddleware_Limit_stacked_reportsMostRestrictive(t *testing.T) {
+	loose := NewRateLimitMiddleware(10, time.Minute)
+	tight := NewRateLimitMiddleware(2, time.Minute, WithName("tight"))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/temp", tight.Limit(), loose.Limit())
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/temp", nil)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("X-RateLimit-Remaining"))
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("POST", "/temp", nil)
+	router.ServeHTTP(w, r)
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("POST", "/temp", nil)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+}
+
+func TestNewRateLimitMiddleware_withStore(t *testing.T) {
+	store := &MockLimiterStore{MockContext: &limiter.Context{Reached: true}}
+	h := NewRateLimitMiddleware(3, time.Minute, WithStore(store))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/temp", h.Limit())
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/temp", nil)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, store, h.store)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+}
+
+func TestRateLimitMiddleware_Limit_headers(t *testing.T) {
+	h := NewRateLimitMiddleware(2, time.Minute)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/temp", h.Limit())
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/temp", nil)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "60", w.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "2", w.Header().Get("X-RateLimit-Limit"))
+	assert.Empty(t, w.Header().Get("Retry-After"))
+}
+
+func TestRateLimitMiddleware_Limit_limitReached_setsRetryAfter(t *testing.T) {
+	clock := &fakeClock{t: time.Now()}
+	tb := NewTokenBucketLimiter(1, 1, 10*time.Second)
+	tb.now = clock.now
+	h := NewRateLimitMiddleware(100, time.Minute, WithRateLimiter(tb))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/temp", h.Limit())
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/temp", nil))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/temp", nil))
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "10", w.Header().Get("Retry-After"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Limit"))
+	assert.JSONEq(t, `{"error": "Too many requests", "retry_after": 10}`, w.Body.String())
+}
+
+func TestRateLimitMiddleware_Limit_tiers(t *testing.T) {
+	h := NewRateLimitMiddleware(
+		1,
+		time.Minute,
+		WithTiers(TierByRole("pro"), map[string]RateLimiter{
+			"pro": NewSlidingWindowLimiter(3, time.Minute),
+		}),
+	)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/temp", func(c *gin.Context) {
+		if role := c.GetHeader("X-Role"); role != "" {
+			c.Request = c.Request.WithContext(
+				utils.WithRoles(c.Request.Context(), []string{role}),
+			)
+		}
+	}, h.Limit())
+
+	serve := func(role string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/temp", nil)
+		r.Header.Set("X-Role", role)
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, serve("").Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve("").Code)
+
+	pro := serve("pro")
+	assert.Equal(t, http.StatusOK, pro.Code)
+	assert.Equal(t, "3", pro.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, http.StatusOK, serve("pro").Code)
+	assert.Equal(t, http.StatusOK, serve("pro").Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve("pro").Code)
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"github.com/Admiral-Piett/go-tools/gin/models"
+	"github.com/Admiral-Piett/go-tools/gin/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TierFunc picks which of a limiter's tiers applies to a request.  Returning "", or a tier
+// that wasn't configured, uses the middleware's default limit.
+type TierFunc func(c *gin.Context) string
+
+// TierByRole uses the first of the given roles the caller has, so list them highest priority
+// first.  Roles are read with `utils.GetRoles`, so it must sit behind whatever middleware the app
+// sets them in with `utils.WithRoles`.
+//
+//	middleware.WithTiers(middleware.TierByRole("admin", "pro"), map[string]middleware.RateLimiter{
+//		"admin": middleware.NewTokenBucketLimiter(1000, 1000, time.Minute),
+//		"pro":   middleware.NewTokenBucketLimiter(200, 100, time.Minute),
+//	})
+func TierByRole(roles ...string) TierFunc {
+	return func(c *gin.Context) string {
+		granted, _ := utils.GetRoles(c)
+		for _, role := range roles {
+			for _, g := range granted {
+				if g == role {
+					return role
+				}
+			}
+		}
+		return ""
+	}
+}
+
+// TierFromClaims picks a tier from anything in the caller's token, e.g. giving service
+// accounts their own limit:
+//
+//	middleware.TierFromClaims(func(claims *models.AuthClaims) string {
+//		if claims.ClientId != "" {
+//			return "service"
+//		}
+//		return ""
+//	})
+func TierFromClaims(fn func(claims *models.AuthClaims) string) TierFunc {
+	return func(c *gin.Context) string {
+		claims, ok := utils.GetClaims(c)
+		if !ok || claims == nil {
+			return ""
+		}
+		return fn(claims)
+	}
+}
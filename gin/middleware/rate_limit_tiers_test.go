@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/Admiral-Piett/go-tools/gin/models"
+	"github.com/Admiral-Piett/go-tools/gin/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTierByRole(t *testing.T) {
+	fn := TierByRole("admin", "pro")
+	setRoles := func(roles ...string) func(c *gin.Context) {
+		return func(c *gin.Context) {
+			c.Request = c.Request.WithContext(utils.WithRoles(c.Request.Context(), roles))
+		}
+	}
+
+	assert.Equal(t, "admin", serveKey(KeyFunc(fn), setRoles("pro", "admin")))
+	assert.Equal(t, "pro", serveKey(KeyFunc(fn), setRoles("user", "pro")))
+	assert.Equal(t, "", serveKey(KeyFunc(fn), setRoles("user")))
+	assert.Equal(t, "", serveKey(KeyFunc(fn), nil))
+}
+
+func TestTierFromClaims(t *testing.T) {
+	fn := TierFromClaims(func(claims *models.AuthClaims) string {
+		if claims.ClientId != "" {
+			return "service"
+		}
+		return ""
+	})
+	setClaims := func(c *gin.Context) {
+		c.Request = c.Request.WithContext(
+			utils.WithClaims(c.Request.Context(), &models.AuthClaims{ClientId: "batch-jobs"}),
+		)
+	}
+
+	assert.Equal(t, "service", serveKey(KeyFunc(fn), setClaims))
+	assert.Equal(t, "", serveKey(KeyFunc(fn), nil))
+}
@@ -0,0 +1,218 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ulule/limiter/v3"
+)
+
+// RateLimiter decides whether one more request for a key is allowed.  Implementations must be
+// safe for concurrent use.
+type RateLimiter interface {
+	Allow(ctx context.Context, key string) (RateLimitResult, error)
+}
+
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int64
+	Remaining int64
+	// ResetAfter is how long until the key is back to its full allowance
+	ResetAfter time.Duration
+	// RetryAfter is how long until the next request would be allowed, zero if it already is
+	RetryAfter time.Duration
+}
+
+// fixedWindowLimiter adapts ulule's limiter, which counts requests in fixed windows.  Cheap and
+// works with shared stores, but a client can send 2x the limit across a window boundary.
+type fixedWindowLimiter struct {
+	limiter *limiter.Limiter
+}
+
+func (f *fixedWindowLimiter) Allow(ctx context.Context, key string) (RateLimitResult, error) {
+	context, err := f.limiter.Get(ctx, key)
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+	resetAfter := time.Until(time.Unix(context.Reset, 0))
+	if resetAfter < 0 {
+		resetAfter = 0
+	}
+	result := RateLimitResult{
+		Allowed:    !context.Reached,
+		Limit:      context.Limit,
+		Remaining:  context.Remaining,
+		ResetAfter: resetAfter,
+	}
+	if context.Reached {
+		result.RetryAfter = resetAfter
+	}
+	return result, nil
+}
+
+// sweepInterval is how often the in memory limiters drop state for idle keys
+const sweepInterval = time.Minute
+
+// TokenBucketLimiter allows bursts of up to `burst` requests, then refills at `limit` per
+// `period`.  Clients that stay under the refill rate are never limited, and there are no
+// window boundaries to game.  State is in memory, so it's per instance.  A burst below 1 allows
+// nothing, and a limit or period of 0 or less never refills.
+type TokenBucketLimiter struct {
+	burst     float64
+	perSecond float64
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func NewTokenBucketLimiter(burst, limit int, period time.Duration) *TokenBucketLimiter {
+	tb := &TokenBucketLimiter{
+		burst:   math.Max(float64(burst), 0),
+		buckets: map[string]*tokenBucket{},
+		now:     time.Now,
+	}
+	if limit > 0 && period > 0 {
+		tb.perSecond = float64(limit) / period.Seconds()
+	}
+	return tb
+}
+
+func (tb *TokenBucketLimiter) Allow(ctx context.Context, key string) (RateLimitResult, error) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	now := tb.now()
+	tb.sweep(now)
+
+	bucket, ok := tb.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: tb.burst, last: now}
+		tb.buckets[key] = bucket
+	}
+	tb.refill(bucket, now)
+
+	result := RateLimitResult{Limit: int64(tb.burst)}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = tb.durationFor(1 - bucket.tokens)
+	}
+	result.Remaining = int64(math.Floor(bucket.tokens))
+	result.ResetAfter = tb.durationFor(tb.burst - bucket.tokens)
+	return result, nil
+}
+
+func (tb *TokenBucketLimiter) refill(bucket *tokenBucket, now time.Time) {
+	elapsed := now.Sub(bucket.last).Seconds()
+	if elapsed > 0 {
+		bucket.tokens = math.Min(tb.burst, bucket.tokens+elapsed*tb.perSecond)
+		bucket.last = now
+	}
+}
+
+// durationFor is how long it takes to refill the given number of tokens
+func (tb *TokenBucketLimiter) durationFor(tokens float64) time.Duration {
+	if tokens <= 0 || tb.perSecond <= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(tokens / tb.perSecond * float64(time.Second)))
+}
+
+// sweep drops buckets that have refilled completely, they're the same as no bucket at all
+func (tb *TokenBucketLimiter) sweep(now time.Time) {
+	if now.Sub(tb.lastSweep) < sweepInterval {
+		return
+	}
+	tb.lastSweep = now
+	for key, bucket := range tb.buckets {
+		tb.refill(bucket, now)
+		if bucket.tokens >= tb.burst {
+			delete(tb.buckets, key)
+		}
+	}
+}
+
+// SlidingWindowLimiter allows `limit` requests in any `window` long span, by remembering when
+// each request was made.  It's exact, but memory grows with the limit, so prefer the token
+// bucket for high limits.  State is in memory, so it's per instance.  A limit of 0 or less
+// allows nothing.
+type SlidingWindowLimiter struct {
+	limit  int
+	window time.Duration
+
+	mu        sync.Mutex
+	logs      map[string][]time.Time
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewSlidingWindowLimiter(limit int, window time.Duration) *SlidingWindowLimiter {
+	if limit < 0 {
+		limit = 0
+	}
+	return &SlidingWindowLimiter{
+		limit:  limit,
+		window: window,
+		logs:   map[string][]time.Time{},
+		now:    time.Now,
+	}
+}
+
+func (sw *SlidingWindowLimiter) Allow(ctx context.Context, key string) (RateLimitResult, error) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	now := sw.now()
+	sw.sweep(now)
+
+	requests := sw.trim(sw.logs[key], now)
+	result := RateLimitResult{Limit: int64(sw.limit)}
+	if len(requests) < sw.limit {
+		requests = append(requests, now)
+		result.Allowed = true
+	} else if len(requests) == 0 {
+		// Nothing is ever allowed, so there's nothing to wait for either
+		result.RetryAfter = sw.window
+	} else {
+		result.RetryAfter = requests[0].Add(sw.window).Sub(now)
+	}
+	sw.logs[key] = requests
+
+	result.Remaining = int64(sw.limit - len(requests))
+	if len(requests) > 0 {
+		result.ResetAfter = requests[len(requests)-1].Add(sw.window).Sub(now)
+	}
+	return result, nil
+}
+
+// trim drops requests that have slid out of the window.  Logs are in time order.
+func (sw *SlidingWindowLimiter) trim(requests []time.Time, now time.Time) []time.Time {
+	cutoff := now.Add(-sw.window)
+	i := 0
+	for i < len(requests) && !requests[i].After(cutoff) {
+		i++
+	}
+	return requests[i:]
+}
+
+func (sw *SlidingWindowLimiter) sweep(now time.Time) {
+	if now.Sub(sw.lastSweep) < sweepInterval {
+		return
+	}
+	sw.lastSweep = now
+	for key, requests := range sw.logs {
+		if len(sw.trim(requests, now)) == 0 {
+			delete(sw.logs, key)
+		}
+	}
+}
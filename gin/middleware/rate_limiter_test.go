@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ulule/limiter/v3"
+	"github.com/ulule/limiter/v3/drivers/store/memory"
+)
+
+// fakeClock lets the in memory limiters be stepped through time
+type fakeClock struct {
+	t time.Time
+}
+
+func (f *fakeClock) now() time.Time {
+	return f.t
+}
+
+func (f *fakeClock) advance(d time.Duration) {
+	f.t = f.t.Add(d)
+}
+
+func TestFixedWindowLimiter_Allow(t *testing.T) {
+	f := &fixedWindowLimiter{limiter: limiter.New(memory.NewStore(), limiter.Rate{
+		Period: time.Minute,
+		Limit:  1,
+	})}
+
+	first, err := f.Allow(context.Background(), "foo")
+	assert.Nil(t, err)
+	assert.True(t, first.Allowed)
+	assert.Equal(t, int64(0), first.Remaining)
+	assert.Zero(t, first.RetryAfter)
+
+	second, err := f.Allow(context.Background(), "foo")
+	assert.Nil(t, err)
+	assert.False(t, second.Allowed)
+	assert.InDelta(t, time.Minute.Seconds(), second.RetryAfter.Seconds(), 1)
+}
+
+func TestTokenBucketLimiter_Allow_burstThenRefill(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1700000000, 0)}
+	tb := NewTokenBucketLimiter(3, 1, time.Second)
+	tb.now = clock.now
+	ctx := context.Background()
+
+	for i := 2; i >= 0; i-- {
+		result, _ := tb.Allow(ctx, "foo")
+		assert.True(t, result.Allowed)
+		assert.Equal(t, int64(i), result.Remaining)
+		assert.Equal(t, int64(3), result.Limit)
+	}
+
+	result, _ := tb.Allow(ctx, "foo")
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second, result.RetryAfter)
+	assert.Equal(t, 3*time.Second, result.ResetAfter)
+
+	clock.advance(500 * time.Millisecond)
+	result, _ = tb.Allow(ctx, "foo")
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 500*time.Millisecond, result.RetryAfter)
+
+	clock.advance(500 * time.Millisecond)
+	result, _ = tb.Allow(ctx, "foo")
+	assert.True(t, result.Allowed)
+
+	other, _ := tb.Allow(ctx, "bar")
+	assert.True(t, other.Allowed)
+	assert.Equal(t, int64(2), other.Remaining)
+}
+
+func TestTokenBucketLimiter_Allow_refillCappedAtBurst(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1700000000, 0)}
+	tb := NewTokenBucketLimiter(2, 1, time.Second)
+	tb.now = clock.now
+	ctx := context.Background()
+
+	tb.Allow(ctx, "foo")
+	clock.advance(time.Hour)
+	result, _ := tb.Allow(ctx, "foo")
+
+	assert.True(t, result.Allowed)
+	assert.Equal(t, int64(1), result.Remaining)
+}
+
+func TestTokenBucketLimiter_sweep_dropsFullBuckets(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1700000000, 0)}
+	tb := NewTokenBucketLimiter(2, 1, time.Second)
+	tb.now = clock.now
+	ctx := context.Background()
+
+	tb.Allow(ctx, "foo")
+	clock.advance(2 * sweepInterval)
+	tb.Allow(ctx, "bar")
+
+	assert.Len(t, tb.buckets, 1)
+	assert.Contains(t, tb.buckets, "bar")
+}
+
+func TestSlidingWindowLimiter_Allow_noBoundaryBurst(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1700000000, 0)}
+	sw := NewSlidingWindowLimiter(2, time.Minute)
+	sw.now = clock.now
+	ctx := context.Background()
+
+	first, _ := sw.Allow(ctx, "foo")
+	assert.True(t, first.Allowed)
+	assert.Equal(t, int64(1), first.Remaining)
+
+	clock.advance(50 * time.Second)
+	second, _ := sw.Allow(ctx, "foo")
+	assert.True(t, second.Allowed)
+	assert.Equal(t, int64(0), second.Remaining)
+	assert.Equal(t, time.Minute, second.ResetAfter)
+
+	// A fixed window would have reset by now
+	clock.advance(20 * time.Second)
+	third, _ := sw.Allow(ctx, "foo")
+	assert.True(t, third.Allowed)
+	fourth, _ := sw.Allow(ctx, "foo")
+	assert.False(t, fourth.Allowed)
+	assert.Equal(t, 40*time.Second, fourth.RetryAfter)
+}
+
+func TestSlidingWindowLimiter_sweep_dropsIdleKeys(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1700000000, 0)}
+	sw := NewSlidingWindowLimiter(2, time.Second)
+	sw.now = clock.now
+	ctx := context.Background()
+
+	sw.Allow(ctx, "foo")
+	clock.advance(2 * sweepInterval)
+	sw.Allow(ctx, "bar")
+
+	assert.Len(t, sw.logs, 1)
+	assert.Contains(t, sw.logs, "bar")
+}
+
+func TestTokenBucketLimiter_Allow_zeroPeriodNeverRefills(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1700000000, 0)}
+	tb := NewTokenBucketLimiter(1, 1, 0)
+	tb.now = clock.now
+	ctx := context.Background()
+
+	first, _ := tb.Allow(ctx, "foo")
+	assert.True(t, first.Allowed)
+
+	clock.advance(time.Hour)
+	second, _ := tb.Allow(ctx, "foo")
+	assert.False(t, second.Allowed)
+}
+
+func TestSlidingWindowLimiter_Allow_zeroLimitDenies(t *testing.T) {
+	for _, limit := range []int{0, -1} {
+		sw := NewSlidingWindowLimiter(limit, time.Minute)
+
+		result, err := sw.Allow(context.Background(), "foo")
+		assert.Nil(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, int64(0), result.Limit)
+		assert.Equal(t, int64(0), result.Remaining)
+		assert.Equal(t, time.Minute, result.RetryAfter)
+	}
+}
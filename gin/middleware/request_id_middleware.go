@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"github.com/Admiral-Piett/go-tools/gin/utils"
+	"github.com/Admiral-Piett/go-tools/logging"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+const RequestIdHeader = "X-Request-Id"
+
+// maxRequestIdLength stops a client from stuffing huge values into every log line
+const maxRequestIdLength = 128
+
+// RequestIdMiddleware gives every request an id, echoed in the `X-Request-Id` response header
+// and available via `utils.GetRequestId`.  It also sets up the request's log fields - request
+// id, route and trace id - for `logging.FromContext`.  Install it first so everything after it
+// can use them.
+//
+//	router.Use(middleware.NewRequestIdMiddleware(false).RequestId(), middleware.AccessLogMiddleware())
+type RequestIdMiddleware struct {
+	trustIncoming bool
+}
+
+// NewRequestIdMiddleware only reuses ids sent by the caller if trustIncoming is set, e.g. when
+// a gateway in front of the app already assigns them.  Otherwise a new id is always generated,
+// so clients can't make their requests look like someone else's in the logs.
+func NewRequestIdMiddleware(trustIncoming bool) *RequestIdMiddleware {
+	return &RequestIdMiddleware{
+		trustIncoming: trustIncoming,
+	}
+}
+
+func (rm *RequestIdMiddleware) RequestId() gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		requestId := ""
+		if rm.trustIncoming {
+			requestId = c.GetHeader(RequestIdHeader)
+			if !validRequestId(requestId) {
+				requestId = ""
+			}
+		}
+		if requestId == "" {
+			requestId = newRequestId()
+		}
+
+		c.Header(RequestIdHeader, requestId)
+		ctx := utils.WithRequestId(c.Request.Context(), requestId)
+
+		fields := log.Fields{"request_id": requestId}
+		if route := c.FullPath(); route != "" {
+			fields["route"] = route
+		}
+		traceId, spanId := logging.ParseTraceContext(
+			c.GetHeader(logging.CloudTraceContextHeader),
+			c.GetHeader(logging.TraceparentHeader),
+		)
+		if traceId != "" {
+			fields[logging.TraceIdField] = traceId
+		}
+		if spanId != "" {
+			fields[logging.SpanIdField] = spanId
+		}
+		ctx = logging.WithFields(ctx, fields)
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	})
+}
+
+// newRequestId returns a random (version 4) UUID
+func newRequestId() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// validRequestId only accepts ids that are safe to put in headers and logs as is
+func validRequestId(requestId string) bool {
+	if requestId == "" || len(requestId) > maxRequestIdLength {
+		return false
+	}
+	for _, r := range requestId {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':', r == '/', r == '+', r == '=':
+		default:
+			return false
+		}
+	}
+	return true
+}
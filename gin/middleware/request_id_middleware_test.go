@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/Admiral-Piett/go-tools/gin/utils"
+	"github.com/Admiral-Piett/go-tools/logging"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func serveRequestId(h *RequestIdMiddleware, incoming string) (*httptest.ResponseRecorder, string) {
+	var requestId string
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/temp", h.RequestId(), func(c *gin.Context) {
+		requestId, _ = utils.GetRequestId(c)
+	})
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/temp", nil)
+	if incoming != "" {
+		r.Header.Set(RequestIdHeader, incoming)
+	}
+	router.ServeHTTP(w, r)
+	return w, requestId
+}
+
+func TestRequestIdMiddleware_RequestId_generated(t *testing.T) {
+	w, requestId := serveRequestId(NewRequestIdMiddleware(false), "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Regexp(t, uuidPattern, requestId)
+	assert.Equal(t, requestId, w.Header().Get(RequestIdHeader))
+}
+
+func TestRequestIdMiddleware_RequestId_untrustedIncoming_replaced(t *testing.T) {
+	w, requestId := serveRequestId(NewRequestIdMiddleware(false), "client-chosen")
+
+	assert.Regexp(t, uuidPattern, requestId)
+	assert.Equal(t, requestId, w.Header().Get(RequestIdHeader))
+}
+
+func TestRequestIdMiddleware_RequestId_trustedIncoming_reused(t *testing.T) {
+	w, requestId := serveRequestId(NewRequestIdMiddleware(true), "gateway-1234")
+
+	assert.Equal(t, "gateway-1234", requestId)
+	assert.Equal(t, "gateway-1234", w.Header().Get(RequestIdHeader))
+}
+
+func TestRequestIdMiddleware_RequestId_trustedIncomingInvalid_replaced(t *testing.T) {
+	_, requestId := serveRequestId(NewRequestIdMiddleware(true), "bad id\nwith newline")
+
+	assert.Regexp(t, uuidPattern, requestId)
+}
+
+func TestRequestIdMiddleware_RequestId_addedToLogEntries(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := log.New()
+	logger.SetOutput(buf)
+	logger.SetFormatter(&log.JSONFormatter{})
+	logger.AddHook(&logging.ContextHook{})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/temp", NewRequestIdMiddleware(true).RequestId(), func(c *gin.Context) {
+		logger.WithContext(c.Request.Context()).Info("handling")
+	})
+	r := httptest.NewRequest("GET", "/temp", nil)
+	r.Header.Set(RequestIdHeader, "gateway-1234")
+	router.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.Contains(t, buf.String(), `"request_id":"gateway-1234"`)
+}
+
+func TestRequestIdMiddleware_RequestId_routeAndTraceFields(t *testing.T) {
+	var fields log.Fields
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/users/:id", NewRequestIdMiddleware(false).RequestId(), func(c *gin.Context) {
+		fields = logging.FieldsFromContext(c.Request.Context())
+	})
+	r := httptest.NewRequest("GET", "/users/7", nil)
+	r.Header.Set(logging.CloudTraceContextHeader, "105445aa7843bc8bf206b12000100000/1;o=1")
+	router.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.Equal(t, "/users/:id", fields["route"])
+	assert.Equal(t, "105445aa7843bc8bf206b12000100000", fields["trace_id"])
+	assert.Equal(t, "0000000000000001", fields["span_id"])
+}
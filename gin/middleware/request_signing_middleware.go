@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Admiral-Piett/go-tools/gin/models"
+	"github.com/Admiral-Piett/go-tools/logging"
+	"github.com/Admiral-Piett/go-tools/settings"
+	"github.com/Admiral-Piett/go-tools/signing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RequestSigningMiddleware struct {
+	verifier *signing.Verifier
+}
+
+// NewRequestSigningMiddleware verifies requests signed with `REQUEST_SIGNING_KEY`.  Pass a
+// shared nonce cache when running more than one instance, or nil for an in memory one.
+func NewRequestSigningMiddleware(
+	cfg *settings.BaseSettings,
+	nonces signing.NonceCacheInterface,
+) (*RequestSigningMiddleware, error) {
+	verifier, err := signing.NewVerifier(
+		cfg.RequestSigningKey,
+		time.Duration(cfg.RequestSigningWindow)*time.Second,
+		cfg.RequestSigningMaxBody,
+		nonces,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &RequestSigningMiddleware{
+		verifier: verifier,
+	}, nil
+}
+
+// RequireSignature rejects any request that wasn't signed by a `signing.SigningTransport`
+// holding the same key.
+func (rsm *RequestSigningMiddleware) RequireSignature() gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		if err := rsm.verifier.Verify(c.Request); err != nil {
+			logging.FromContext(c.Request.Context()).
+				WithError(err).
+				Warning("Verify Request Signature Failure")
+
+			if errors.Is(err, signing.ErrBodyTooLarge) {
+				c.AbortWithStatusJSON(
+					http.StatusRequestEntityTooLarge,
+					models.ErrorResponses.RequestTooLargeError,
+				)
+				return
+			}
+
+			c.AbortWithStatusJSON(
+				http.StatusUnauthorized,
+				models.ErrorResponses.UnauthorizedError,
+			)
+			return
+		}
+
+		c.Next()
+	})
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Admiral-Piett/go-tools/settings"
+	"github.com/Admiral-Piett/go-tools/signing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+var requestSigningKey = "39ec2ad652a6b32df6664711e13e74f6388f2a67550397e8b97212471e35042e3f5b716d45fc5d65854c48c95c722178c058b69fd2f611ccf6af54ea3db854b8"
+
+func TestNewRequestSigningMiddleware_invalidKey_error(t *testing.T) {
+	_, err := NewRequestSigningMiddleware(&settings.BaseSettings{
+		RequestSigningKey: "garbage",
+	}, nil)
+
+	assert.Error(t, err)
+}
+
+func TestRequestSigningMiddleware_RequireSignature_success(t *testing.T) {
+	h, err := NewRequestSigningMiddleware(&settings.BaseSettings{
+		RequestSigningKey:    requestSigningKey,
+		RequestSigningWindow: 60,
+	}, nil)
+	assert.Nil(t, err)
+
+	var body string
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/temp", h.RequireSignature(), func(c *gin.Context) {
+		b, _ := c.GetRawData()
+		body = string(b)
+	})
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/temp", strings.NewReader("payload"))
+	key, _ := signing.DecodeKey(requestSigningKey)
+	signing.SignRequest(r, key, time.Now())
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "payload", body)
+}
+
+func TestRequestSigningMiddleware_RequireSignature_bodyTooLarge_413(t *testing.T) {
+	h, _ := NewRequestSigningMiddleware(&settings.BaseSettings{
+		RequestSigningKey:     requestSigningKey,
+		RequestSigningWindow:  60,
+		RequestSigningMaxBody: 4,
+	}, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/temp", h.RequireSignature())
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/temp", strings.NewReader("payload"))
+	key, _ := signing.DecodeKey(requestSigningKey)
+	signing.SignRequest(r, key, time.Now())
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestRequestSigningMiddleware_RequireSignature_unsigned_401(t *testing.T) {
+	h, _ := NewRequestSigningMiddleware(&settings.BaseSettings{
+		RequestSigningKey:    requestSigningKey,
+		RequestSigningWindow: 60,
+	}, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/temp", h.RequireSignature())
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/temp", strings.NewReader("payload"))
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
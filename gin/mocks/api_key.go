@@ -0,0 +1,63 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/Admiral-Piett/go-tools/gin/models"
+)
+
+type MockAPIKeyService struct {
+	CreateAPIKeyCalledWith   []interface{}
+	ValidateAPIKeyCalledWith []interface{}
+	ListAPIKeysCalledWith    []interface{}
+	RevokeAPIKeyCalledWith   []interface{}
+
+	MockCreateAPIKey   func(userId string, name string, scopes []string, expiresAt *time.Time) (string, *models.APIKey, error)
+	MockValidateAPIKey func(rawKey string) (*models.APIKey, error)
+	MockListAPIKeys    func(userId string) ([]models.APIKey, error)
+	MockRevokeAPIKey   func(userId string, prefix string) error
+}
+
+func (m *MockAPIKeyService) CreateAPIKey(
+	userId string,
+	name string,
+	scopes []string,
+	expiresAt *time.Time,
+) (string, *models.APIKey, error) {
+	m.CreateAPIKeyCalledWith = []interface{}{userId, name, scopes, expiresAt}
+	if m.MockCreateAPIKey != nil {
+		return m.MockCreateAPIKey(userId, name, scopes, expiresAt)
+	}
+	return "", &models.APIKey{}, nil
+}
+
+func (m *MockAPIKeyService) ValidateAPIKey(
+	rawKey string,
+) (*models.APIKey, error) {
+	m.ValidateAPIKeyCalledWith = []interface{}{rawKey}
+	if m.MockValidateAPIKey != nil {
+		return m.MockValidateAPIKey(rawKey)
+	}
+	return &models.APIKey{UserId: "1"}, nil
+}
+
+func (m *MockAPIKeyService) ListAPIKeys(
+	userId string,
+) ([]models.APIKey, error) {
+	m.ListAPIKeysCalledWith = []interface{}{userId}
+	if m.MockListAPIKeys != nil {
+		return m.MockListAPIKeys(userId)
+	}
+	return []models.APIKey{}, nil
+}
+
+func (m *MockAPIKeyService) RevokeAPIKey(
+	userId string,
+	prefix string,
+) error {
+	m.RevokeAPIKeyCalledWith = []interface{}{userId, prefix}
+	if m.MockRevokeAPIKey != nil {
+		return m.MockRevokeAPIKey(userId, prefix)
+	}
+	return nil
+}
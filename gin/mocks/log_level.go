@@ -0,0 +1,43 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/Admiral-Piett/go-tools/logging"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type MockLogLevelController struct {
+	StatusCalled       bool
+	SetLevelCalledWith []interface{}
+	ResetCalled        bool
+
+	MockStatus   func() logging.LevelStatus
+	MockSetLevel func(level log.Level, ttl time.Duration) logging.LevelStatus
+	MockReset    func() logging.LevelStatus
+}
+
+func (m *MockLogLevelController) Status() logging.LevelStatus {
+	m.StatusCalled = true
+	if m.MockStatus != nil {
+		return m.MockStatus()
+	}
+	return logging.LevelStatus{Level: "info", BaseLevel: "info"}
+}
+
+func (m *MockLogLevelController) SetLevel(level log.Level, ttl time.Duration) logging.LevelStatus {
+	m.SetLevelCalledWith = []interface{}{level, ttl}
+	if m.MockSetLevel != nil {
+		return m.MockSetLevel(level, ttl)
+	}
+	return logging.LevelStatus{Level: level.String(), BaseLevel: "info"}
+}
+
+func (m *MockLogLevelController) Reset() logging.LevelStatus {
+	m.ResetCalled = true
+	if m.MockReset != nil {
+		return m.MockReset()
+	}
+	return logging.LevelStatus{Level: "info", BaseLevel: "info"}
+}
@@ -0,0 +1,56 @@
+package mocks
+
+import "github.com/Admiral-Piett/go-tools/gin/models"
+
+type MockOAuthClientService struct {
+	CreateClientCalledWith       []interface{}
+	AuthenticateClientCalledWith []interface{}
+	GetClientCalledWith          []interface{}
+	RevokeClientCalledWith       []interface{}
+
+	MockCreateClient       func(name string, scopes []string) (string, *models.OAuthClient, error)
+	MockAuthenticateClient func(clientId, clientSecret string) (*models.OAuthClient, error)
+	MockGetClient          func(clientId string) (*models.OAuthClient, error)
+	MockRevokeClient       func(clientId string) error
+}
+
+func (m *MockOAuthClientService) CreateClient(
+	name string,
+	scopes []string,
+) (string, *models.OAuthClient, error) {
+	m.CreateClientCalledWith = []interface{}{name, scopes}
+	if m.MockCreateClient != nil {
+		return m.MockCreateClient(name, scopes)
+	}
+	return "", &models.OAuthClient{}, nil
+}
+
+func (m *MockOAuthClientService) AuthenticateClient(
+	clientId, clientSecret string,
+) (*models.OAuthClient, error) {
+	m.AuthenticateClientCalledWith = []interface{}{clientId, clientSecret}
+	if m.MockAuthenticateClient != nil {
+		return m.MockAuthenticateClient(clientId, clientSecret)
+	}
+	return &models.OAuthClient{ClientId: clientId}, nil
+}
+
+func (m *MockOAuthClientService) GetClient(
+	clientId string,
+) (*models.OAuthClient, error) {
+	m.GetClientCalledWith = []interface{}{clientId}
+	if m.MockGetClient != nil {
+		return m.MockGetClient(clientId)
+	}
+	return &models.OAuthClient{ClientId: clientId}, nil
+}
+
+func (m *MockOAuthClientService) RevokeClient(
+	clientId string,
+) error {
+	m.RevokeClientCalledWith = []interface{}{clientId}
+	if m.MockRevokeClient != nil {
+		return m.MockRevokeClient(clientId)
+	}
+	return nil
+}
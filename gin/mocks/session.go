@@ -0,0 +1,92 @@
+package mocks
+
+import "github.com/Admiral-Piett/go-tools/gin/models"
+
+type MockSessionService struct {
+	CreateSessionCalledWith       []interface{}
+	RefreshSessionCalledWith      []interface{}
+	IsSessionActiveCalledWith     []interface{}
+	ListActiveSessionsCalledWith  []interface{}
+	RevokeSessionCalledWith       []interface{}
+	RevokeDeviceSessionCalledWith []interface{}
+	RevokeAllSessionsCalledWith   []interface{}
+
+	MockCreateSession       func(userId, deviceToken, userAgent, ipAddress string) (*models.Session, error)
+	MockRefreshSession      func(userId, deviceToken, userAgent, ipAddress string) (*models.Session, error)
+	MockIsSessionActive     func(userId, deviceToken string) (bool, error)
+	MockListActiveSessions  func(userId string) ([]models.Session, error)
+	MockRevokeSession       func(userId string, sessionId uint) error
+	MockRevokeDeviceSession func(userId, deviceToken string) error
+	MockRevokeAllSessions   func(userId string) error
+}
+
+func (m *MockSessionService) CreateSession(
+	userId, deviceToken, userAgent, ipAddress string,
+) (*models.Session, error) {
+	m.CreateSessionCalledWith = []interface{}{userId, deviceToken, userAgent, ipAddress}
+	if m.MockCreateSession != nil {
+		return m.MockCreateSession(userId, deviceToken, userAgent, ipAddress)
+	}
+	return &models.Session{}, nil
+}
+
+func (m *MockSessionService) RefreshSession(
+	userId, deviceToken, userAgent, ipAddress string,
+) (*models.Session, error) {
+	m.RefreshSessionCalledWith = []interface{}{userId, deviceToken, userAgent, ipAddress}
+	if m.MockRefreshSession != nil {
+		return m.MockRefreshSession(userId, deviceToken, userAgent, ipAddress)
+	}
+	return &models.Session{}, nil
+}
+
+func (m *MockSessionService) IsSessionActive(
+	userId, deviceToken string,
+) (bool, error) {
+	m.IsSessionActiveCalledWith = []interface{}{userId, deviceToken}
+	if m.MockIsSessionActive != nil {
+		return m.MockIsSessionActive(userId, deviceToken)
+	}
+	return true, nil
+}
+
+func (m *MockSessionService) ListActiveSessions(
+	userId string,
+) ([]models.Session, error) {
+	m.ListActiveSessionsCalledWith = []interface{}{userId}
+	if m.MockListActiveSessions != nil {
+		return m.MockListActiveSessions(userId)
+	}
+	return []models.Session{}, nil
+}
+
+func (m *MockSessionService) RevokeSession(
+	userId string,
+	sessionId uint,
+) error {
+	m.RevokeSessionCalledWith = []interface{}{userId, sessionId}
+	if m.MockRevokeSession != nil {
+		return m.MockRevokeSession(userId, sessionId)
+	}
+	return nil
+}
+
+func (m *MockSessionService) RevokeDeviceSession(
+	userId, deviceToken string,
+) error {
+	m.RevokeDeviceSessionCalledWith = []interface{}{userId, deviceToken}
+	if m.MockRevokeDeviceSession != nil {
+		return m.MockRevokeDeviceSession(userId, deviceToken)
+	}
+	return nil
+}
+
+func (m *MockSessionService) RevokeAllSessions(
+	userId string,
+) error {
+	m.RevokeAllSessionsCalledWith = []interface{}{userId}
+	if m.MockRevokeAllSessions != nil {
+		return m.MockRevokeAllSessions(userId)
+	}
+	return nil
+}
@@ -1,20 +1,32 @@
 package mocks
 
 import (
+	"strconv"
+
 	"github.com/Admiral-Piett/go-tools/gin/interfaces"
 	"github.com/Admiral-Piett/go-tools/gin/models"
+
+	"github.com/golang-jwt/jwt"
 )
 
 type MockTokenService struct {
-	GenerateTokenResponseCalledWith []interface{}
-	ValidateAccessTokenCalledWith   []interface{}
-	ValidateRefreshTokenCalledWith  []interface{}
-	DecryptUserIDCalledWith         []interface{}
+	GenerateTokenResponseCalledWith           []interface{}
+	GenerateStringUserTokenResponseCalledWith []interface{}
+	GenerateClientTokenResponseCalledWith     []interface{}
+	ValidateAccessTokenCalledWith             []interface{}
+	ValidateRefreshTokenCalledWith            []interface{}
+	ValidateRefreshTokenClaimsCalledWith      []interface{}
+	DecryptUserIDCalledWith                   []interface{}
+	DecryptUserIDStringCalledWith             []interface{}
 
-	MockGenerateTokenResponse func(user interfaces.UserModelInterface) (*models.TokenResponse, error)
-	MockValidateAccessToken   func(tokenString string) (*models.AuthClaims, error)
-	MockValidateRefreshToken  func(tokenString string) (string, error)
-	MockDecryptUserID         func(encryptedUserID string) (int, error)
+	MockGenerateTokenResponse           func(user interfaces.UserModelInterface) (*models.TokenResponse, error)
+	MockGenerateStringUserTokenResponse func(user interfaces.StringUserModelInterface) (*models.TokenResponse, error)
+	MockGenerateClientTokenResponse     func(clientId string, scopes []string) (*models.TokenResponse, error)
+	MockValidateAccessToken             func(tokenString string) (*models.AuthClaims, error)
+	MockValidateRefreshToken            func(tokenString string) (string, error)
+	MockValidateRefreshTokenClaims      func(tokenString string) (*models.RefreshClaims, error)
+	MockDecryptUserID                   func(encryptedUserID string) (int, error)
+	MockDecryptUserIDString             func(encryptedUserID string) (string, error)
 }
 
 func (m *MockTokenService) GenerateTokenResponse(
@@ -27,6 +39,27 @@ func (m *MockTokenService) GenerateTokenResponse(
 	return &models.TokenResponse{}, nil
 }
 
+func (m *MockTokenService) GenerateStringUserTokenResponse(
+	user interfaces.StringUserModelInterface,
+) (*models.TokenResponse, error) {
+	m.GenerateStringUserTokenResponseCalledWith = []interface{}{user}
+	if m.MockGenerateStringUserTokenResponse != nil {
+		return m.MockGenerateStringUserTokenResponse(user)
+	}
+	return &models.TokenResponse{}, nil
+}
+
+func (m *MockTokenService) GenerateClientTokenResponse(
+	clientId string,
+	scopes []string,
+) (*models.TokenResponse, error) {
+	m.GenerateClientTokenResponseCalledWith = []interface{}{clientId, scopes}
+	if m.MockGenerateClientTokenResponse != nil {
+		return m.MockGenerateClientTokenResponse(clientId, scopes)
+	}
+	return &models.TokenResponse{}, nil
+}
+
 func (m *MockTokenService) ValidateAccessToken(
 	tokenString string,
 ) (*models.AuthClaims, error) {
@@ -47,6 +80,21 @@ func (m *MockTokenService) ValidateRefreshToken(
 	return "", nil
 }
 
+func (m *MockTokenService) ValidateRefreshTokenClaims(
+	tokenString string,
+) (*models.RefreshClaims, error) {
+	m.ValidateRefreshTokenClaimsCalledWith = []interface{}{tokenString}
+	if m.MockValidateRefreshTokenClaims != nil {
+		return m.MockValidateRefreshTokenClaims(tokenString)
+	}
+	// Tests written before the claims existed only set MockValidateRefreshToken
+	encryptedUserID, err := m.ValidateRefreshToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	return &models.RefreshClaims{StandardClaims: jwt.StandardClaims{Id: encryptedUserID}}, nil
+}
+
 func (m *MockTokenService) DecryptUserID(
 	encryptedUserID string,
 ) (int, error) {
@@ -56,3 +104,18 @@ func (m *MockTokenService) DecryptUserID(
 	}
 	return 0, nil
 }
+
+func (m *MockTokenService) DecryptUserIDString(
+	encryptedUserID string,
+) (string, error) {
+	m.DecryptUserIDStringCalledWith = []interface{}{encryptedUserID}
+	if m.MockDecryptUserIDString != nil {
+		return m.MockDecryptUserIDString(encryptedUserID)
+	}
+	// Tests written against int ids only set MockDecryptUserID
+	userId, err := m.DecryptUserID(encryptedUserID)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(userId), nil
+}
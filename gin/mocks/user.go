@@ -23,3 +23,27 @@ func (m *UserMock) GetDeviceToken() string {
 	}
 	return "device-token"
 }
+
+type StringUserMock struct {
+	GetUserIdStringCalled bool
+	GetDeviceTokenCalled  bool
+
+	MockGetUserIdString func() string
+	MockGetDeviceToken  func() string
+}
+
+func (m *StringUserMock) GetUserIdString() string {
+	m.GetUserIdStringCalled = true
+	if m.MockGetUserIdString != nil {
+		return m.MockGetUserIdString()
+	}
+	return "2b1c3f9e-5d4a-4e0b-9a57-6f1d2c3b4a5e"
+}
+
+func (m *StringUserMock) GetDeviceToken() string {
+	m.GetDeviceTokenCalled = true
+	if m.MockGetDeviceToken != nil {
+		return m.MockGetDeviceToken()
+	}
+	return "device-token"
+}
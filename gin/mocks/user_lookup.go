@@ -0,0 +1,59 @@
+package mocks
+
+import "github.com/Admiral-Piett/go-tools/gin/interfaces"
+
+type MockUserLookup struct {
+	FindUserByUsernameCalledWith []interface{}
+	FindUserByIdCalledWith       []interface{}
+
+	MockFindUserByUsername func(username string) (interfaces.UserModelInterface, string, string, error)
+	MockFindUserById       func(userId int) (interfaces.UserModelInterface, error)
+}
+
+func (m *MockUserLookup) FindUserByUsername(
+	username string,
+) (interfaces.UserModelInterface, string, string, error) {
+	m.FindUserByUsernameCalledWith = []interface{}{username}
+	if m.MockFindUserByUsername != nil {
+		return m.MockFindUserByUsername(username)
+	}
+	return &UserMock{}, "", "", nil
+}
+
+func (m *MockUserLookup) FindUserById(
+	userId int,
+) (interfaces.UserModelInterface, error) {
+	m.FindUserByIdCalledWith = []interface{}{userId}
+	if m.MockFindUserById != nil {
+		return m.MockFindUserById(userId)
+	}
+	return &UserMock{}, nil
+}
+
+type MockStringUserLookup struct {
+	FindUserByUsernameCalledWith []interface{}
+	FindUserByIdCalledWith       []interface{}
+
+	MockFindUserByUsername func(username string) (interfaces.StringUserModelInterface, string, string, error)
+	MockFindUserById       func(userId string) (interfaces.StringUserModelInterface, error)
+}
+
+func (m *MockStringUserLookup) FindUserByUsername(
+	username string,
+) (interfaces.StringUserModelInterface, string, string, error) {
+	m.FindUserByUsernameCalledWith = []interface{}{username}
+	if m.MockFindUserByUsername != nil {
+		return m.MockFindUserByUsername(username)
+	}
+	return &StringUserMock{}, "", "", nil
+}
+
+func (m *MockStringUserLookup) FindUserById(
+	userId string,
+) (interfaces.StringUserModelInterface, error) {
+	m.FindUserByIdCalledWith = []interface{}{userId}
+	if m.MockFindUserById != nil {
+		return m.MockFindUserById(userId)
+	}
+	return &StringUserMock{}, nil
+}
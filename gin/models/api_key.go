@@ -0,0 +1,75 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// APIKey is the stored record for a machine client key.  Only the prefix is kept in the clear,
+// the secret half of the key is stored as an HMAC so a leaked table can't be replayed.
+//
+// UserId is stored as a string so keys work regardless of the app's primary key type.
+type APIKey struct {
+	Id         uint       `gorm:"primaryKey" json:"id"`
+	Prefix     string     `gorm:"uniqueIndex;not null" json:"prefix"`
+	SecretHash string     `gorm:"not null" json:"-"`
+	Name       string     `gorm:"not null" json:"name"`
+	UserId     string     `gorm:"index;not null" json:"user_id"`
+	Scopes     string     `gorm:"not null" json:"scopes"`
+	CreatedAt  time.Time  `gorm:"not null" json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+func (k *APIKey) TableName() string {
+	return "api_keys"
+}
+
+// ScopeList returns the key's scopes as a slice
+func (k *APIKey) ScopeList() []string {
+	return ParseScopes(k.Scopes)
+}
+
+// HasScopes reports whether the key was granted every one of the required scopes
+func (k *APIKey) HasScopes(required ...string) bool {
+	return HasScopes(k.ScopeList(), required...)
+}
+
+// IsActive reports whether the key is neither revoked nor expired at the given time
+func (k *APIKey) IsActive(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	if k.ExpiresAt != nil && !now.Before(*k.ExpiresAt) {
+		return false
+	}
+	return true
+}
+
+// ParseScopes splits a space delimited scope string (the OAuth2 convention) into a slice
+func ParseScopes(scopes string) []string {
+	return strings.Fields(scopes)
+}
+
+// JoinScopes is the inverse of ParseScopes
+func JoinScopes(scopes []string) string {
+	return strings.Join(scopes, " ")
+}
+
+// HasScopes reports whether every required scope is present in granted
+func HasScopes(granted []string, required ...string) bool {
+	for _, r := range required {
+		found := false
+		for _, g := range granted {
+			if g == r {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
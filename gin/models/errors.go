@@ -19,14 +19,34 @@ func init() {
 			Code:    "UNAUTHORIZED",
 			Message: "Unauthorized",
 		},
+		ForbiddenError: ErrorResponse{
+			Code:    "FORBIDDEN",
+			Message: "Forbidden",
+		},
+		NotFoundError: ErrorResponse{
+			Code:    "NOT_FOUND",
+			Message: "Not Found",
+		},
+		RequestTooLargeError: ErrorResponse{
+			Code:    "REQUEST_TOO_LARGE",
+			Message: "Request Too Large",
+		},
+		ServiceUnavailableError: ErrorResponse{
+			Code:    "SERVICE_UNAVAILABLE",
+			Message: "Server busy, please try again",
+		},
 	}
 }
 
 var ErrorResponses *errorResponses
 
 type errorResponses struct {
-	GeneralError      ErrorResponse
-	BadRequest        ErrorResponse
-	ValidationError   ErrorResponse
-	UnauthorizedError ErrorResponse
+	GeneralError            ErrorResponse
+	BadRequest              ErrorResponse
+	ValidationError         ErrorResponse
+	UnauthorizedError       ErrorResponse
+	ForbiddenError          ErrorResponse
+	NotFoundError           ErrorResponse
+	RequestTooLargeError    ErrorResponse
+	ServiceUnavailableError ErrorResponse
 }
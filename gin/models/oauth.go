@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+// OAuthClient is a service account that can obtain tokens via the client credentials grant.
+// The secret is stored hashed with `password.HashPassword`.
+type OAuthClient struct {
+	Id         uint       `gorm:"primaryKey" json:"id"`
+	ClientId   string     `gorm:"uniqueIndex;not null" json:"client_id"`
+	SecretHash string     `gorm:"not null" json:"-"`
+	SecretSalt string     `gorm:"not null" json:"-"`
+	Name       string     `gorm:"not null" json:"name"`
+	Scopes     string     `gorm:"not null" json:"scopes"`
+	CreatedAt  time.Time  `gorm:"not null" json:"created_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+func (o *OAuthClient) TableName() string {
+	return "oauth_clients"
+}
+
+func (o *OAuthClient) ScopeList() []string {
+	return ParseScopes(o.Scopes)
+}
+
+// IntrospectScope has to be granted to a client before it can use the introspection endpoint
+const IntrospectScope = "introspect"
+
+// OAuthTokenResponse is the RFC 6749 section 5.1 token response
+type OAuthTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	Scope       string `json:"scope,omitempty"`
+}
+
+// RFC 6749 section 5.2 error codes
+const (
+	OAuthInvalidRequest       = "invalid_request"
+	OAuthInvalidClient        = "invalid_client"
+	OAuthInvalidScope         = "invalid_scope"
+	OAuthUnsupportedGrantType = "unsupported_grant_type"
+	OAuthServerError          = "server_error"
+	// RFC 6750 section 3.1, for clients lacking a scope an endpoint needs
+	OAuthInsufficientScope = "insufficient_scope"
+)
+
+// OAuthErrorResponse is the RFC 6749 section 5.2 error response.  OAuth endpoints use this
+// rather than ErrorResponse since clients expect the standard shape.
+type OAuthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// IntrospectionResponse is the RFC 7662 section 2.2 introspection response.  Inactive tokens
+// only ever return `{"active": false}`, nothing else about them is disclosed.
+type IntrospectionResponse struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientId  string `json:"client_id,omitempty"`
+	Sub       string `json:"sub,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+	Nbf       int64  `json:"nbf,omitempty"`
+	Iss       string `json:"iss,omitempty"`
+}
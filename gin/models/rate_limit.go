@@ -0,0 +1,13 @@
+package models
+
+// RateLimitCounter is one fixed window bucket for the database rate limit store.  ExpiresAt is
+// unix milliseconds rather than a timestamp so it compares the same way in every database.
+type RateLimitCounter struct {
+	BucketKey string `gorm:"primaryKey"`
+	Count     int64  `gorm:"not null"`
+	ExpiresAt int64  `gorm:"not null;index"`
+}
+
+func (r *RateLimitCounter) TableName() string {
+	return "rate_limit_counters"
+}
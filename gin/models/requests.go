@@ -3,8 +3,15 @@ package models
 type PostLoginRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	// DeviceToken identifies the device logging in, defaulting to the user model's device
+	DeviceToken string `json:"device_token"`
 }
 
 type PostRefreshRequest struct {
 	RefreshToken string `json:"refresh_token"`
 }
+
+type PutLogLevelRequest struct {
+	Level      string `json:"level"`
+	TtlSeconds int    `json:"ttl_seconds"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Session is one logged in device for a user.  There is at most one row per user and device,
+// logging in again from the same device reuses (and un-revokes) it.
+type Session struct {
+	Id          uint       `gorm:"primaryKey" json:"id"`
+	UserId      string     `gorm:"uniqueIndex:idx_sessions_user_device;not null" json:"-"`
+	DeviceToken string     `gorm:"uniqueIndex:idx_sessions_user_device;not null" json:"device_token"`
+	UserAgent   string     `gorm:"not null" json:"user_agent"`
+	IpAddress   string     `gorm:"not null" json:"ip_address"`
+	CreatedAt   time.Time  `gorm:"not null" json:"created_at"`
+	LastSeenAt  time.Time  `gorm:"not null" json:"last_seen_at"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+}
+
+func (s *Session) TableName() string {
+	return "sessions"
+}
+
+type SessionResponse struct {
+	Session
+	Current bool `json:"current"`
+}
+
+type SessionsResponse struct {
+	Sessions []SessionResponse `json:"sessions"`
+}
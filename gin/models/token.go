@@ -2,8 +2,19 @@ package models
 
 import "github.com/golang-jwt/jwt"
 
+// AuthClaims are the access token claims.  User tokens carry EncryptedUserID, service account
+// tokens from the client credentials grant carry ClientId and Scope instead.
 type AuthClaims struct {
-	EncryptedUserID string `json:"uid"`
+	EncryptedUserID string `json:"uid,omitempty"`
 	DeviceToken     string `json:"device,omitempty"`
+	ClientId        string `json:"client_id,omitempty"`
+	Scope           string `json:"scope,omitempty"`
+	jwt.StandardClaims
+}
+
+// RefreshClaims are the refresh token claims.  Id holds the encrypted user id and DeviceToken
+// the device the token was issued to, so a refresh is checked against that device's session.
+type RefreshClaims struct {
+	DeviceToken string `json:"device,omitempty"`
 	jwt.StandardClaims
 }
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Admiral-Piett/go-tools/encryption"
+	"github.com/Admiral-Piett/go-tools/gin/interfaces"
+	"github.com/Admiral-Piett/go-tools/gin/models"
+	"github.com/Admiral-Piett/go-tools/gorm/database"
+	dbInterfaces "github.com/Admiral-Piett/go-tools/gorm/interfaces"
+	"github.com/Admiral-Piett/go-tools/settings"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAPIKeyInvalid  = errors.New("api key invalid")
+	ErrAPIKeyNotFound = errors.New("api key not found")
+)
+
+// APIKeyMigration creates the `api_keys` table.  Register it alongside your own migrations:
+//
+//	database.RegisterMigration(services.APIKeyMigration)
+var APIKeyMigration = database.Migration{
+	Id:          "gotools_001_create_api_keys_table",
+	Description: "Create api_keys table for machine client authentication",
+	Up: func(tx *gorm.DB) error {
+		return tx.AutoMigrate(&models.APIKey{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&models.APIKey{})
+	},
+}
+
+// Raw keys look like `<prefix>_<public id>_<secret>`, e.g. `sk_1a2b3c4d5e6f7a8b_<64 hex chars>`.
+// Everything before the last underscore is stored in the clear so keys can be identified in
+// logs and UIs, the secret is only ever stored as an HMAC.
+const (
+	apiKeyPublicIdBytes = 8
+	apiKeySecretBytes   = 32
+)
+
+type APIKeyService struct {
+	db        dbInterfaces.DatabaseInterface
+	hmacKey   []byte
+	keyPrefix string
+}
+
+func NewAPIKeyService(
+	cfg *settings.BaseSettings,
+	db dbInterfaces.DatabaseInterface,
+) interfaces.APIKeyServiceInterface {
+	decodedHmacKey, _ := hex.DecodeString(cfg.ApiKeyHmacKey)
+	return &APIKeyService{
+		db:        db,
+		hmacKey:   decodedHmacKey,
+		keyPrefix: cfg.ApiKeyPrefix,
+	}
+}
+
+// CreateAPIKey generates and stores a new key.  The raw key is only returned here, it can't be
+// recovered later so it needs to be handed to the client immediately.
+func (s *APIKeyService) CreateAPIKey(
+	userId string,
+	name string,
+	scopes []string,
+	expiresAt *time.Time,
+) (string, *models.APIKey, error) {
+	publicId := make([]byte, apiKeyPublicIdBytes)
+	if _, err := rand.Read(publicId); err != nil {
+		return "", nil, err
+	}
+	secret := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, err
+	}
+
+	prefix := hex.EncodeToString(publicId)
+	if s.keyPrefix != "" {
+		prefix = fmt.Sprintf("%s_%s", s.keyPrefix, prefix)
+	}
+	secretString := hex.EncodeToString(secret)
+
+	key := &models.APIKey{
+		Prefix:     prefix,
+		SecretHash: encryption.SignHMAC(secretString, s.hmacKey),
+		Name:       name,
+		UserId:     userId,
+		Scopes:     models.JoinScopes(scopes),
+		CreatedAt:  time.Now().UTC(),
+		ExpiresAt:  expiresAt,
+	}
+	if err := s.db.DB().Create(key).Error; err != nil {
+		return "", nil, err
+	}
+
+	return fmt.Sprintf("%s_%s", prefix, secretString), key, nil
+}
+
+// ValidateAPIKey looks up the key by its prefix, verifies the secret and checks it hasn't been
+// revoked or expired.  On success the key's last used timestamp is bumped.
+func (s *APIKeyService) ValidateAPIKey(rawKey string) (*models.APIKey, error) {
+	i := strings.LastIndex(rawKey, "_")
+	if i <= 0 || i == len(rawKey)-1 {
+		return nil, ErrAPIKeyInvalid
+	}
+	prefix, secret := rawKey[:i], rawKey[i+1:]
+
+	key := &models.APIKey{}
+	err := s.db.Model(&models.APIKey{}).Where("prefix = ?", prefix).First(key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAPIKeyInvalid
+		}
+		return nil, err
+	}
+
+	if !encryption.VerifyHMAC(secret, key.SecretHash, s.hmacKey) {
+		return nil, ErrAPIKeyInvalid
+	}
+
+	now := time.Now().UTC()
+	if !key.IsActive(now) {
+		return nil, errors.New("api key revoked or expired")
+	}
+
+	// Failing to record usage shouldn't fail the request
+	err = s.db.Model(key).UpdateColumn("last_used_at", now).Error
+	if err != nil {
+		log.WithError(err).WithField("api_key_prefix", key.Prefix).
+			Warning("Unable to record API key usage")
+	} else {
+		key.LastUsedAt = &now
+	}
+
+	return key, nil
+}
+
+// ListAPIKeys returns all keys belonging to a user, including revoked ones, newest first
+func (s *APIKeyService) ListAPIKeys(userId string) ([]models.APIKey, error) {
+	keys := []models.APIKey{}
+	err := s.db.Model(&models.APIKey{}).
+		Where("user_id = ?", userId).
+		Order("created_at DESC").
+		Find(&keys).Error
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey marks a user's key as revoked.  Revoked keys are kept for auditing.
+func (s *APIKeyService) RevokeAPIKey(userId string, prefix string) error {
+	result := s.db.Model(&models.APIKey{}).
+		Where("user_id = ? AND prefix = ? AND revoked_at IS NULL", userId, prefix).
+		UpdateColumn("revoked_at", time.Now().UTC())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Admiral-Piett/go-tools/gin/models"
+	"github.com/Admiral-Piett/go-tools/gorm/database"
+	"github.com/Admiral-Piett/go-tools/settings"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestAPIKeyService(t *testing.T) *APIKeyService {
+	db := database.NewTestDatabase(t, APIKeyMigration)
+
+	decodedHmacKey, _ := hex.DecodeString(hmacKey)
+	return &APIKeyService{
+		db:        db,
+		hmacKey:   decodedHmacKey,
+		keyPrefix: "sk",
+	}
+}
+
+func TestNewAPIKeyService(t *testing.T) {
+	s := NewAPIKeyService(&settings.BaseSettings{
+		JwtHmacKey:    "00",
+		ApiKeyHmacKey: hmacKey,
+		ApiKeyPrefix:  "pk",
+	}, nil).(*APIKeyService)
+
+	assert.Equal(t, "pk", s.keyPrefix)
+	assert.Len(t, s.hmacKey, 64)
+}
+
+func TestAPIKeyService_CreateAPIKey_success(t *testing.T) {
+	s := newTestAPIKeyService(t)
+
+	rawKey, key, err := s.CreateAPIKey("1", "partner", []string{"read", "write"}, nil)
+
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(rawKey, key.Prefix+"_"))
+	assert.True(t, strings.HasPrefix(key.Prefix, "sk_"))
+	assert.NotContains(t, key.SecretHash, rawKey[len(key.Prefix)+1:])
+	assert.Equal(t, "read write", key.Scopes)
+	assert.Equal(t, "1", key.UserId)
+	assert.NotZero(t, key.Id)
+}
+
+func TestAPIKeyService_ValidateAPIKey_success(t *testing.T) {
+	s := newTestAPIKeyService(t)
+	rawKey, created, _ := s.CreateAPIKey("1", "partner", []string{"read"}, nil)
+
+	result, err := s.ValidateAPIKey(rawKey)
+
+	assert.Nil(t, err)
+	assert.Equal(t, created.Id, result.Id)
+	assert.NotNil(t, result.LastUsedAt)
+
+	stored := &models.APIKey{}
+	s.db.DB().First(stored, created.Id)
+	assert.NotNil(t, stored.LastUsedAt)
+}
+
+func TestAPIKeyService_ValidateAPIKey_malformed_error(t *testing.T) {
+	s := newTestAPIKeyService(t)
+
+	for _, rawKey := range []string{"", "garbage", "_secret", "sk_abc_"} {
+		_, err := s.ValidateAPIKey(rawKey)
+		assert.ErrorIs(t, err, ErrAPIKeyInvalid, rawKey)
+	}
+}
+
+func TestAPIKeyService_ValidateAPIKey_unknownPrefix_error(t *testing.T) {
+	s := newTestAPIKeyService(t)
+
+	_, err := s.ValidateAPIKey("sk_0000000000000000_secret")
+
+	assert.ErrorIs(t, err, ErrAPIKeyInvalid)
+}
+
+func TestAPIKeyService_ValidateAPIKey_wrongSecret_error(t *testing.T) {
+	s := newTestAPIKeyService(t)
+	_, created, _ := s.CreateAPIKey("1", "partner", nil, nil)
+
+	_, err := s.ValidateAPIKey(created.Prefix + "_garbage")
+
+	assert.ErrorIs(t, err, ErrAPIKeyInvalid)
+}
+
+func TestAPIKeyService_ValidateAPIKey_expired_error(t *testing.T) {
+	s := newTestAPIKeyService(t)
+	expiresAt := time.Now().Add(-1 * time.Minute)
+	rawKey, _, _ := s.CreateAPIKey("1", "partner", nil, &expiresAt)
+
+	_, err := s.ValidateAPIKey(rawKey)
+
+	assert.Error(t, err)
+}
+
+func TestAPIKeyService_RevokeAPIKey_success(t *testing.T) {
+	s := newTestAPIKeyService(t)
+	rawKey, created, _ := s.CreateAPIKey("1", "partner", nil, nil)
+
+	err := s.RevokeAPIKey("1", created.Prefix)
+	assert.Nil(t, err)
+
+	_, err = s.ValidateAPIKey(rawKey)
+	assert.Error(t, err)
+}
+
+func TestAPIKeyService_RevokeAPIKey_otherUsersKey_error(t *testing.T) {
+	s := newTestAPIKeyService(t)
+	rawKey, created, _ := s.CreateAPIKey("1", "partner", nil, nil)
+
+	err := s.RevokeAPIKey("2", created.Prefix)
+	assert.ErrorIs(t, err, ErrAPIKeyNotFound)
+
+	_, err = s.ValidateAPIKey(rawKey)
+	assert.Nil(t, err)
+}
+
+func TestAPIKeyService_ListAPIKeys_success(t *testing.T) {
+	s := newTestAPIKeyService(t)
+	s.CreateAPIKey("1", "first", nil, nil)
+	s.CreateAPIKey("1", "second", nil, nil)
+	s.CreateAPIKey("2", "other", nil, nil)
+
+	result, err := s.ListAPIKeys("1")
+
+	assert.Nil(t, err)
+	assert.Len(t, result, 2)
+	for _, k := range result {
+		assert.Equal(t, "1", k.UserId)
+	}
+}
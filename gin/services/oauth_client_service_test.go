@@ -0,0 +1,72 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Admiral-Piett/go-tools/gorm/database"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestOAuthClientService(t *testing.T) *OAuthClientService {
+	db := database.NewTestDatabase(t, OAuthClientMigration)
+	return &OAuthClientService{db: db}
+}
+
+func TestOAuthClientService_CreateClient_success(t *testing.T) {
+	s := newTestOAuthClientService(t)
+
+	secret, client, err := s.CreateClient("batch-jobs", []string{"jobs:read", "jobs:write"})
+
+	assert.Nil(t, err)
+	assert.Len(t, secret, 43)
+	assert.Len(t, client.ClientId, 32)
+	assert.NotEqual(t, secret, client.SecretHash)
+	assert.Equal(t, "batch-jobs", client.Name)
+	assert.Equal(t, []string{"jobs:read", "jobs:write"}, client.ScopeList())
+}
+
+func TestOAuthClientService_AuthenticateClient_success(t *testing.T) {
+	s := newTestOAuthClientService(t)
+	secret, created, _ := s.CreateClient("batch-jobs", []string{"jobs:read"})
+
+	result, err := s.AuthenticateClient(created.ClientId, secret)
+
+	assert.Nil(t, err)
+	assert.Equal(t, created.Id, result.Id)
+}
+
+func TestOAuthClientService_AuthenticateClient_wrongSecret_error(t *testing.T) {
+	s := newTestOAuthClientService(t)
+	_, created, _ := s.CreateClient("batch-jobs", []string{"jobs:read"})
+
+	_, err := s.AuthenticateClient(created.ClientId, "wrong")
+
+	assert.ErrorIs(t, err, ErrClientInvalid)
+}
+
+func TestOAuthClientService_AuthenticateClient_unknown_error(t *testing.T) {
+	s := newTestOAuthClientService(t)
+
+	_, err := s.AuthenticateClient("unknown", "secret")
+
+	assert.ErrorIs(t, err, ErrClientInvalid)
+}
+
+func TestOAuthClientService_AuthenticateClient_revoked_error(t *testing.T) {
+	s := newTestOAuthClientService(t)
+	secret, created, _ := s.CreateClient("batch-jobs", []string{"jobs:read"})
+	s.RevokeClient(created.ClientId)
+
+	_, err := s.AuthenticateClient(created.ClientId, secret)
+
+	assert.ErrorIs(t, err, ErrClientInvalid)
+}
+
+func TestOAuthClientService_RevokeClient_unknown_error(t *testing.T) {
+	s := newTestOAuthClientService(t)
+
+	err := s.RevokeClient("unknown")
+
+	assert.ErrorIs(t, err, ErrClientNotFound)
+}
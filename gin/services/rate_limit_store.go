@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Admiral-Piett/go-tools/gin/interfaces"
+	"github.com/Admiral-Piett/go-tools/gin/models"
+	"github.com/Admiral-Piett/go-tools/gorm/database"
+	dbInterfaces "github.com/Admiral-Piett/go-tools/gorm/interfaces"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/ulule/limiter/v3"
+	"github.com/ulule/limiter/v3/drivers/store/common"
+	"gorm.io/gorm"
+)
+
+// RateLimitCounterMigration creates the `rate_limit_counters` table.  Register it alongside your
+// own migrations:
+//
+//	database.RegisterMigration(services.RateLimitCounterMigration)
+var RateLimitCounterMigration = database.Migration{
+	Id:          "gotools_004_create_rate_limit_counters_table",
+	Description: "Create rate_limit_counters table for rate limits shared between instances",
+	Up: func(tx *gorm.DB) error {
+		return tx.AutoMigrate(&models.RateLimitCounter{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&models.RateLimitCounter{})
+	},
+}
+
+// incrementCounterSQL bumps a bucket in one statement, starting a fresh window if the old one
+// has expired.  `ON CONFLICT ... RETURNING` works in both Postgres and SQLite 3.35+.
+const incrementCounterSQL = `
+INSERT INTO rate_limit_counters (bucket_key, count, expires_at) VALUES (?, ?, ?)
+ON CONFLICT (bucket_key) DO UPDATE SET
+	count = CASE
+		WHEN rate_limit_counters.expires_at <= ? THEN excluded.count
+		ELSE rate_limit_counters.count + excluded.count
+	END,
+	expires_at = CASE
+		WHEN rate_limit_counters.expires_at <= ? THEN excluded.expires_at
+		ELSE rate_limit_counters.expires_at
+	END
+RETURNING count, expires_at`
+
+// RateLimitStore is a `limiter.Store` backed by the database, so every instance of an app
+// counts against the same buckets.  Pass it to `middleware.WithStore`.  Expired buckets stay in
+// the table until deleted, either by `WithSweepInterval` or by calling `DeleteExpired` yourself:
+//
+//	store := services.NewRateLimitStore(db, services.WithSweepInterval(time.Minute))
+//	defer store.Close()
+type RateLimitStore struct {
+	db dbInterfaces.DatabaseInterface
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+type RateLimitStoreOption func(*RateLimitStore)
+
+// WithSweepInterval deletes expired buckets in the background every interval until Close.
+// Every instance can run one, deletes are idempotent.
+func WithSweepInterval(interval time.Duration) RateLimitStoreOption {
+	return func(s *RateLimitStore) {
+		if interval <= 0 {
+			return
+		}
+		s.stop = make(chan struct{})
+		s.done = make(chan struct{})
+		go s.sweep(interval)
+	}
+}
+
+func NewRateLimitStore(
+	db dbInterfaces.DatabaseInterface,
+	opts ...RateLimitStoreOption,
+) interfaces.RateLimitStoreInterface {
+	s := &RateLimitStore{
+		db: db,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Get increments the bucket by one and returns its new state
+func (s *RateLimitStore) Get(
+	ctx context.Context,
+	key string,
+	rate limiter.Rate,
+) (limiter.Context, error) {
+	return s.Increment(ctx, key, 1, rate)
+}
+
+// Increment adds count to the bucket and returns its new state
+func (s *RateLimitStore) Increment(
+	ctx context.Context,
+	key string,
+	count int64,
+	rate limiter.Rate,
+) (limiter.Context, error) {
+	now := time.Now()
+	nowMs := now.UnixMilli()
+	expiresAt := now.Add(rate.Period).UnixMilli()
+
+	counter := models.RateLimitCounter{}
+	err := s.db.DB().WithContext(ctx).
+		Raw(incrementCounterSQL, key, count, expiresAt, nowMs, nowMs).
+		Scan(&counter).Error
+	if err != nil {
+		return limiter.Context{}, err
+	}
+	return common.GetContextFromState(now, rate, time.UnixMilli(counter.ExpiresAt), counter.Count), nil
+}
+
+// Peek returns the bucket's state without counting a request
+func (s *RateLimitStore) Peek(
+	ctx context.Context,
+	key string,
+	rate limiter.Rate,
+) (limiter.Context, error) {
+	now := time.Now()
+
+	counters := []models.RateLimitCounter{}
+	err := s.db.DB().WithContext(ctx).
+		Where("bucket_key = ? AND expires_at > ?", key, now.UnixMilli()).
+		Limit(1).
+		Find(&counters).Error
+	if err != nil {
+		return limiter.Context{}, err
+	}
+	if len(counters) == 0 {
+		return common.GetContextFromState(now, rate, now.Add(rate.Period), 0), nil
+	}
+	return common.GetContextFromState(
+		now,
+		rate,
+		time.UnixMilli(counters[0].ExpiresAt),
+		counters[0].Count,
+	), nil
+}
+
+// Reset empties the bucket
+func (s *RateLimitStore) Reset(
+	ctx context.Context,
+	key string,
+	rate limiter.Rate,
+) (limiter.Context, error) {
+	now := time.Now()
+	err := s.db.DB().WithContext(ctx).
+		Where("bucket_key = ?", key).
+		Delete(&models.RateLimitCounter{}).Error
+	if err != nil {
+		return limiter.Context{}, err
+	}
+	return common.GetContextFromState(now, rate, now.Add(rate.Period), 0), nil
+}
+
+// DeleteExpired removes buckets whose window has passed, returning how many were removed
+func (s *RateLimitStore) DeleteExpired(ctx context.Context) (int64, error) {
+	result := s.db.DB().WithContext(ctx).
+		Where("expires_at <= ?", time.Now().UnixMilli()).
+		Delete(&models.RateLimitCounter{})
+	return result.RowsAffected, result.Error
+}
+
+// Close stops the background sweeper, if there is one
+func (s *RateLimitStore) Close() error {
+	if s.stop == nil {
+		return nil
+	}
+	s.once.Do(func() {
+		close(s.stop)
+		<-s.done
+	})
+	return nil
+}
+
+func (s *RateLimitStore) sweep(interval time.Duration) {
+	defer close(s.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := s.DeleteExpired(context.Background()); err != nil {
+				log.WithError(err).Error("Delete Expired Rate Limit Counters Failure")
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
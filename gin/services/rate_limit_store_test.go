@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Admiral-Piett/go-tools/gin/models"
+	"github.com/Admiral-Piett/go-tools/gorm/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ulule/limiter/v3"
+	"github.com/ulule/limiter/v3/drivers/store/tests"
+)
+
+func newTestRateLimitStore(t *testing.T) *RateLimitStore {
+	db := database.NewTestDatabase(t, RateLimitCounterMigration)
+	return &RateLimitStore{db: db}
+}
+
+func TestRateLimitStore_sequentialAccess(t *testing.T) {
+	tests.TestStoreSequentialAccess(t, newTestRateLimitStore(t))
+}
+
+func TestRateLimitStore_Increment_expiredWindowRestarts(t *testing.T) {
+	s := newTestRateLimitStore(t)
+	rate := limiter.Rate{Period: time.Minute, Limit: 3}
+	s.db.DB().Create(&models.RateLimitCounter{
+		BucketKey: "foo",
+		Count:     10,
+		ExpiresAt: time.Now().Add(-time.Second).UnixMilli(),
+	})
+
+	result, err := s.Increment(context.Background(), "foo", 1, rate)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), result.Remaining)
+	assert.False(t, result.Reached)
+	assert.InDelta(t, time.Now().Add(time.Minute).Unix(), result.Reset, 1)
+}
+
+func TestRateLimitStore_Peek_expiredWindow_empty(t *testing.T) {
+	s := newTestRateLimitStore(t)
+	rate := limiter.Rate{Period: time.Minute, Limit: 3}
+	s.db.DB().Create(&models.RateLimitCounter{
+		BucketKey: "foo",
+		Count:     10,
+		ExpiresAt: time.Now().Add(-time.Second).UnixMilli(),
+	})
+
+	result, err := s.Peek(context.Background(), "foo", rate)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), result.Remaining)
+}
+
+func TestRateLimitStore_DeleteExpired_success(t *testing.T) {
+	s := newTestRateLimitStore(t)
+	s.db.DB().Create(&models.RateLimitCounter{
+		BucketKey: "expired",
+		Count:     1,
+		ExpiresAt: time.Now().Add(-time.Second).UnixMilli(),
+	})
+	s.db.DB().Create(&models.RateLimitCounter{
+		BucketKey: "live",
+		Count:     1,
+		ExpiresAt: time.Now().Add(time.Minute).UnixMilli(),
+	})
+
+	deleted, err := s.DeleteExpired(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), deleted)
+	var count int64
+	s.db.Model(&models.RateLimitCounter{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestRateLimitStore_WithSweepInterval_deletesExpired(t *testing.T) {
+	db := database.NewTestDatabase(t, RateLimitCounterMigration)
+	db.DB().Create(&models.RateLimitCounter{
+		BucketKey: "expired",
+		Count:     1,
+		ExpiresAt: time.Now().Add(-time.Second).UnixMilli(),
+	})
+
+	s := NewRateLimitStore(db, WithSweepInterval(10*time.Millisecond))
+
+	assert.Eventually(t, func() bool {
+		var count int64
+		db.Model(&models.RateLimitCounter{}).Count(&count)
+		return count == 0
+	}, time.Second, 10*time.Millisecond)
+	assert.Nil(t, s.Close())
+	assert.Nil(t, s.Close())
+}
+
+func TestRateLimitStore_Close_withoutSweeper(t *testing.T) {
+	s := newTestRateLimitStore(t)
+
+	assert.Nil(t, s.Close())
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Admiral-Piett/go-tools/gin/interfaces"
+	"github.com/Admiral-Piett/go-tools/gin/models"
+	"github.com/Admiral-Piett/go-tools/gorm/database"
+	dbInterfaces "github.com/Admiral-Piett/go-tools/gorm/interfaces"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrSessionRevoked  = errors.New("session revoked")
+)
+
+// SessionMigration creates the `sessions` table.  Register it alongside your own migrations:
+//
+//	database.RegisterMigration(services.SessionMigration)
+var SessionMigration = database.Migration{
+	Id:          "gotools_002_create_sessions_table",
+	Description: "Create sessions table for per device session management",
+	Up: func(tx *gorm.DB) error {
+		return tx.AutoMigrate(&models.Session{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&models.Session{})
+	},
+}
+
+// SessionService tracks which devices a user is logged in on.  Sessions are created at login
+// and touched on every refresh, revoking one stops its refresh token from being exchanged, so
+// the device is logged out once its current access token expires.
+type SessionService struct {
+	db dbInterfaces.DatabaseInterface
+}
+
+func NewSessionService(
+	db dbInterfaces.DatabaseInterface,
+) interfaces.SessionServiceInterface {
+	return &SessionService{
+		db: db,
+	}
+}
+
+// CreateSession records a login, reusing and reactivating the row if this device has logged in before
+func (s *SessionService) CreateSession(
+	userId, deviceToken, userAgent, ipAddress string,
+) (*models.Session, error) {
+	now := time.Now().UTC()
+	session := &models.Session{
+		UserId:      userId,
+		DeviceToken: deviceToken,
+		UserAgent:   userAgent,
+		IpAddress:   ipAddress,
+		CreatedAt:   now,
+		LastSeenAt:  now,
+	}
+
+	err := s.db.DB().Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "device_token"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"user_agent",
+			"ip_address",
+			"created_at",
+			"last_seen_at",
+			"revoked_at",
+		}),
+	}).Create(session).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// The id isn't reliably returned on conflict, so read back the row we landed on
+	return s.findSession(userId, deviceToken)
+}
+
+// RefreshSession updates a session's last seen details.  It fails for unknown or revoked
+// sessions, which is what stops a revoked device from refreshing its tokens.
+func (s *SessionService) RefreshSession(
+	userId, deviceToken, userAgent, ipAddress string,
+) (*models.Session, error) {
+	session, err := s.findSession(userId, deviceToken)
+	if err != nil {
+		return nil, err
+	}
+	if session.RevokedAt != nil {
+		return nil, ErrSessionRevoked
+	}
+
+	session.UserAgent = userAgent
+	session.IpAddress = ipAddress
+	session.LastSeenAt = time.Now().UTC()
+	err = s.db.Model(session).Updates(map[string]interface{}{
+		"user_agent":   session.UserAgent,
+		"ip_address":   session.IpAddress,
+		"last_seen_at": session.LastSeenAt,
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (s *SessionService) IsSessionActive(userId, deviceToken string) (bool, error) {
+	var count int64
+	err := s.db.Model(&models.Session{}).
+		Where("user_id = ? AND device_token = ? AND revoked_at IS NULL", userId, deviceToken).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// ListActiveSessions returns a user's un-revoked sessions, most recently seen first
+func (s *SessionService) ListActiveSessions(userId string) ([]models.Session, error) {
+	sessions := []models.Session{}
+	err := s.db.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userId).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (s *SessionService) RevokeSession(userId string, sessionId uint) error {
+	result := s.db.Model(&models.Session{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionId, userId).
+		UpdateColumn("revoked_at", time.Now().UTC())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// RevokeDeviceSession revokes the session for one device, e.g. on logout
+func (s *SessionService) RevokeDeviceSession(userId, deviceToken string) error {
+	result := s.db.Model(&models.Session{}).
+		Where("user_id = ? AND device_token = ? AND revoked_at IS NULL", userId, deviceToken).
+		UpdateColumn("revoked_at", time.Now().UTC())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+func (s *SessionService) RevokeAllSessions(userId string) error {
+	return s.db.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userId).
+		UpdateColumn("revoked_at", time.Now().UTC()).
+		Error
+}
+
+func (s *SessionService) findSession(userId, deviceToken string) (*models.Session, error) {
+	session := &models.Session{}
+	err := s.db.Model(&models.Session{}).
+		Where("user_id = ? AND device_token = ?", userId, deviceToken).
+		First(session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Admiral-Piett/go-tools/gorm/database"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSessionService(t *testing.T) *SessionService {
+	db := database.NewTestDatabase(t, SessionMigration)
+	return &SessionService{db: db}
+}
+
+func TestSessionService_CreateSession_success(t *testing.T) {
+	s := newTestSessionService(t)
+
+	result, err := s.CreateSession("1", "device-a", "curl/8.0", "10.0.0.1")
+
+	assert.Nil(t, err)
+	assert.NotZero(t, result.Id)
+	assert.Equal(t, "1", result.UserId)
+	assert.Equal(t, "device-a", result.DeviceToken)
+	assert.Equal(t, "curl/8.0", result.UserAgent)
+	assert.Equal(t, "10.0.0.1", result.IpAddress)
+	assert.Nil(t, result.RevokedAt)
+}
+
+func TestSessionService_CreateSession_sameDeviceReactivates(t *testing.T) {
+	s := newTestSessionService(t)
+	first, _ := s.CreateSession("1", "device-a", "curl/8.0", "10.0.0.1")
+	s.RevokeSession("1", first.Id)
+
+	result, err := s.CreateSession("1", "device-a", "curl/8.1", "10.0.0.2")
+
+	assert.Nil(t, err)
+	assert.Equal(t, first.Id, result.Id)
+	assert.Equal(t, "curl/8.1", result.UserAgent)
+	assert.Nil(t, result.RevokedAt)
+}
+
+func TestSessionService_RefreshSession_success(t *testing.T) {
+	s := newTestSessionService(t)
+	first, _ := s.CreateSession("1", "device-a", "curl/8.0", "10.0.0.1")
+
+	result, err := s.RefreshSession("1", "device-a", "curl/8.0", "10.0.0.9")
+
+	assert.Nil(t, err)
+	assert.Equal(t, first.Id, result.Id)
+	assert.Equal(t, "10.0.0.9", result.IpAddress)
+	assert.False(t, result.LastSeenAt.Before(first.LastSeenAt))
+}
+
+func TestSessionService_RefreshSession_unknown_error(t *testing.T) {
+	s := newTestSessionService(t)
+
+	_, err := s.RefreshSession("1", "device-a", "curl/8.0", "10.0.0.1")
+
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+}
+
+func TestSessionService_RefreshSession_revoked_error(t *testing.T) {
+	s := newTestSessionService(t)
+	first, _ := s.CreateSession("1", "device-a", "curl/8.0", "10.0.0.1")
+	s.RevokeSession("1", first.Id)
+
+	_, err := s.RefreshSession("1", "device-a", "curl/8.0", "10.0.0.1")
+
+	assert.ErrorIs(t, err, ErrSessionRevoked)
+}
+
+func TestSessionService_IsSessionActive(t *testing.T) {
+	s := newTestSessionService(t)
+	first, _ := s.CreateSession("1", "device-a", "curl/8.0", "10.0.0.1")
+
+	active, err := s.IsSessionActive("1", "device-a")
+	assert.Nil(t, err)
+	assert.True(t, active)
+
+	s.RevokeSession("1", first.Id)
+
+	active, err = s.IsSessionActive("1", "device-a")
+	assert.Nil(t, err)
+	assert.False(t, active)
+}
+
+func TestSessionService_ListActiveSessions_success(t *testing.T) {
+	s := newTestSessionService(t)
+	s.CreateSession("1", "device-a", "curl/8.0", "10.0.0.1")
+	revoked, _ := s.CreateSession("1", "device-b", "curl/8.0", "10.0.0.1")
+	s.CreateSession("2", "device-c", "curl/8.0", "10.0.0.1")
+	s.RevokeSession("1", revoked.Id)
+
+	result, err := s.ListActiveSessions("1")
+
+	assert.Nil(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, "device-a", result[0].DeviceToken)
+}
+
+func TestSessionService_RevokeSession_otherUsersSession_error(t *testing.T) {
+	s := newTestSessionService(t)
+	first, _ := s.CreateSession("1", "device-a", "curl/8.0", "10.0.0.1")
+
+	err := s.RevokeSession("2", first.Id)
+
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+}
+
+func TestSessionService_RevokeAllSessions_success(t *testing.T) {
+	s := newTestSessionService(t)
+	s.CreateSession("1", "device-a", "curl/8.0", "10.0.0.1")
+	s.CreateSession("1", "device-b", "curl/8.0", "10.0.0.1")
+	s.CreateSession("2", "device-c", "curl/8.0", "10.0.0.1")
+
+	err := s.RevokeAllSessions("1")
+	assert.Nil(t, err)
+
+	result, _ := s.ListActiveSessions("1")
+	assert.Len(t, result, 0)
+	result, _ = s.ListActiveSessions("2")
+	assert.Len(t, result, 1)
+}
+
+func TestSessionService_RevokeDeviceSession_success(t *testing.T) {
+	s := newTestSessionService(t)
+	s.CreateSession("1", "device-a", "curl/8.0", "10.0.0.1")
+	s.CreateSession("1", "device-b", "curl/8.0", "10.0.0.1")
+
+	err := s.RevokeDeviceSession("1", "device-a")
+	assert.Nil(t, err)
+
+	result, _ := s.ListActiveSessions("1")
+	assert.Len(t, result, 1)
+	assert.Equal(t, "device-b", result[0].DeviceToken)
+
+	err = s.RevokeDeviceSession("1", "device-a")
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+}
@@ -26,6 +26,25 @@ type TokenService struct {
 func NewTokenService(
 	cfg *settings.BaseSettings,
 ) interfaces.TokenServiceInterface {
+	return newTokenService(cfg)
+}
+
+// NewStringUserTokenService is NewTokenService for apps with string or UUID user ids
+func NewStringUserTokenService(
+	cfg *settings.BaseSettings,
+) interfaces.StringUserTokenServiceInterface {
+	return newTokenService(cfg)
+}
+
+// NewClientTokenService is NewTokenService for `handlers.NewOAuthHandler`, which only issues
+// tokens to OAuth clients
+func NewClientTokenService(
+	cfg *settings.BaseSettings,
+) interfaces.ClientTokenServiceInterface {
+	return newTokenService(cfg)
+}
+
+func newTokenService(cfg *settings.BaseSettings) *TokenService {
 	decodedJwtHmacKey, _ := hex.DecodeString(cfg.JwtHmacKey)
 	decodedEncryptionKey, _ := hex.DecodeString(cfg.EncryptionKey)
 	return &TokenService{
@@ -39,12 +58,24 @@ func NewTokenService(
 
 func (ts *TokenService) GenerateTokenResponse(
 	user interfaces.UserModelInterface,
+) (*models.TokenResponse, error) {
+	return ts.generateTokenResponse(strconv.Itoa(user.GetUserId()), user.GetDeviceToken())
+}
+
+// GenerateStringUserTokenResponse implements interfaces.StringUserTokenServiceInterface
+func (ts *TokenService) GenerateStringUserTokenResponse(
+	user interfaces.StringUserModelInterface,
+) (*models.TokenResponse, error) {
+	return ts.generateTokenResponse(user.GetUserIdString(), user.GetDeviceToken())
+}
+
+// generateTokenResponse issues the token pair for a user id of either type
+func (ts *TokenService) generateTokenResponse(
+	userId string,
+	deviceToken string,
 ) (*models.TokenResponse, error) {
 	// Encrypt user ID
-	encryptedID, err := encryption.EncryptAES(
-		strconv.Itoa(user.GetUserId()),
-		ts.encryptionKey,
-	)
+	encryptedID, err := encryption.EncryptAES(userId, ts.encryptionKey)
 	if err != nil {
 		return nil, err
 	}
@@ -56,7 +87,7 @@ func (ts *TokenService) GenerateTokenResponse(
 	// Access token claims
 	accessClaims := &models.AuthClaims{
 		EncryptedUserID: encryptedID,
-		DeviceToken:     user.GetDeviceToken(),
+		DeviceToken:     deviceToken,
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: accessExp.Unix(),
 			IssuedAt:  now.Unix(),
@@ -74,12 +105,15 @@ func (ts *TokenService) GenerateTokenResponse(
 	}
 
 	// Refresh token (simpler claims)
-	refreshClaims := &jwt.StandardClaims{
-		ExpiresAt: refreshExp.Unix(),
-		IssuedAt:  now.Unix(),
-		Issuer:    "polytracker",
-		Subject:   "refresh",
-		Id:        encryptedID,
+	refreshClaims := &models.RefreshClaims{
+		DeviceToken: deviceToken,
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: refreshExp.Unix(),
+			IssuedAt:  now.Unix(),
+			Issuer:    "polytracker",
+			Subject:   "refresh",
+			Id:        encryptedID,
+		},
 	}
 
 	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
@@ -95,6 +129,39 @@ func (ts *TokenService) GenerateTokenResponse(
 	}, nil
 }
 
+// GenerateClientTokenResponse issues an access token for a service account.  There's no refresh
+// token, clients just repeat the client credentials grant (RFC 6749 section 4.4.3).
+func (ts *TokenService) GenerateClientTokenResponse(
+	clientId string,
+	scopes []string,
+) (*models.TokenResponse, error) {
+	now := time.Now()
+	accessExp := now.Add(ts.accessTTL)
+
+	accessClaims := &models.AuthClaims{
+		ClientId: clientId,
+		Scope:    models.JoinScopes(scopes),
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: accessExp.Unix(),
+			IssuedAt:  now.Unix(),
+			NotBefore: now.Unix(),
+			Issuer:    ts.appName,
+			Subject:   "access",
+		},
+	}
+
+	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
+	accessTokenString, err := accessToken.SignedString(ts.jwtSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TokenResponse{
+		AccessToken: accessTokenString,
+		ExpiresAt:   accessExp,
+	}, nil
+}
+
 func (ts *TokenService) ValidateAccessToken(
 	tokenString string,
 ) (*models.AuthClaims, error) {
@@ -126,10 +193,21 @@ func (ts *TokenService) ValidateAccessToken(
 	return claims, nil
 }
 
-func (ts *TokenService) ValidateRefreshToken(
+// ValidateRefreshToken returns the encrypted user id from the refresh token
+func (ts *TokenService) ValidateRefreshToken(tokenString string) (string, error) {
+	claims, err := ts.ValidateRefreshTokenClaims(tokenString)
+	if err != nil {
+		return "", err
+	}
+	return claims.Id, nil
+}
+
+// ValidateRefreshTokenClaims implements interfaces.RefreshClaimsValidator, the encrypted user id
+// is in `Id`
+func (ts *TokenService) ValidateRefreshTokenClaims(
 	tokenString string,
-) (string, error) {
-	claims := &jwt.StandardClaims{}
+) (*models.RefreshClaims, error) {
+	claims := &models.RefreshClaims{}
 
 	token, err := jwt.ParseWithClaims(
 		tokenString,
@@ -146,22 +224,29 @@ func (ts *TokenService) ValidateRefreshToken(
 		},
 	)
 	if err != nil {
-		return "", errors.New(
+		return nil, errors.New(
 			err.Error(),
 		) // repackage the internal errors so their easily accessible for logging
 	}
 	if !token.Valid || claims.Subject != "refresh" {
-		return "", errors.New("token invalid")
+		return nil, errors.New("token invalid")
 	}
 
-	// Return encrypted user ID from the refresh token
-	return claims.Id, nil
+	return claims, nil
 }
 
+// DecryptUserID is for apps with int user ids, use DecryptUserIDString for anything else
 func (ts *TokenService) DecryptUserID(encryptedUserID string) (int, error) {
-	stringValue, err := encryption.DecryptAES(encryptedUserID, ts.encryptionKey)
+	stringValue, err := ts.DecryptUserIDString(encryptedUserID)
 	if err != nil {
 		return 0, err
 	}
 	return strconv.Atoi(stringValue)
 }
+
+// DecryptUserIDString implements interfaces.StringUserTokenServiceInterface, returning the user
+// id exactly as it was encoded, whatever its type.  Use `utils.ParseUserId` to convert it to your
+// own id type.
+func (ts *TokenService) DecryptUserIDString(encryptedUserID string) (string, error) {
+	return encryption.DecryptAES(encryptedUserID, ts.encryptionKey)
+}
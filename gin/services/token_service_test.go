@@ -4,6 +4,7 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"github.com/Admiral-Piett/go-tools/encryption"
+	"github.com/Admiral-Piett/go-tools/gin/interfaces"
 	"github.com/Admiral-Piett/go-tools/gin/mocks"
 	"strconv"
 	"testing"
@@ -40,6 +41,33 @@ func TestTokenService_GenerateTokenResponse_success(t *testing.T) {
 	assert.NotNil(t, result.ExpiresAt)
 }
 
+func TestTokenService_ValidateRefreshToken_success(t *testing.T) {
+	user := &mocks.UserMock{}
+	s := NewTokenService(&settings.BaseSettings{
+		EncryptionKey:      encryptionKey,
+		JwtHmacKey:         hmacKey,
+		JwtAccessTokenTTL:  1,
+		JwtRefreshTokenTTL: 2,
+	})
+	result, _ := s.GenerateTokenResponse(user)
+
+	claims, err := s.(interfaces.RefreshClaimsValidator).ValidateRefreshTokenClaims(result.RefreshToken)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "device-token", claims.DeviceToken)
+	userId, err := s.DecryptUserID(claims.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, userId)
+
+	encryptedUserId, err := s.ValidateRefreshToken(result.RefreshToken)
+	assert.Nil(t, err)
+	assert.Equal(t, claims.Id, encryptedUserId)
+
+	// Access tokens aren't accepted in their place
+	_, err = s.ValidateRefreshToken(result.AccessToken)
+	assert.Error(t, err)
+}
+
 func TestTokenService_GenerateTokenResponse_unableToEncryptUserId_error(
 	t *testing.T,
 ) {
@@ -191,3 +219,63 @@ func TestTokenService_DecryptUserID_invalidInput(t *testing.T) {
 
 	assert.Error(t, err)
 }
+
+func TestTokenService_GenerateStringUserTokenResponse_success(t *testing.T) {
+	s := NewStringUserTokenService(&settings.BaseSettings{
+		EncryptionKey:      encryptionKey,
+		JwtHmacKey:         hmacKey,
+		JwtAccessTokenTTL:  1,
+		JwtRefreshTokenTTL: 2,
+	})
+	user := &mocks.StringUserMock{}
+
+	result, err := s.GenerateStringUserTokenResponse(user)
+	assert.Nil(t, err)
+	assert.True(t, user.GetUserIdStringCalled)
+
+	claims, err := s.ValidateAccessToken(result.AccessToken)
+	assert.Nil(t, err)
+	userId, err := s.DecryptUserIDString(claims.EncryptedUserID)
+	assert.Nil(t, err)
+	assert.Equal(t, "2b1c3f9e-5d4a-4e0b-9a57-6f1d2c3b4a5e", userId)
+
+	// Not an int, so the int accessor refuses it
+	_, err = s.DecryptUserID(claims.EncryptedUserID)
+	assert.Error(t, err)
+}
+
+func TestTokenService_DecryptUserIDString_success(t *testing.T) {
+	encryptionKey := make([]byte, 32)
+	rand.Read(encryptionKey)
+
+	encryptedID, _ := encryption.EncryptAES("abc-123", encryptionKey)
+
+	ts := &TokenService{
+		encryptionKey: encryptionKey,
+	}
+	result, err := ts.DecryptUserIDString(encryptedID)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "abc-123", result)
+}
+
+func TestTokenService_GenerateClientTokenResponse_success(t *testing.T) {
+	decodedJwtHmacKey, _ := hex.DecodeString(hmacKey)
+	s := &TokenService{
+		jwtSecret: decodedJwtHmacKey,
+		appName:   "go-tools",
+		accessTTL: 15 * time.Minute,
+	}
+
+	result, err := s.GenerateClientTokenResponse("client-id", []string{"jobs:read", "jobs:write"})
+
+	assert.Nil(t, err)
+	assert.Empty(t, result.RefreshToken)
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), result.ExpiresAt, time.Second)
+
+	claims, err := s.ValidateAccessToken(result.AccessToken)
+	assert.Nil(t, err)
+	assert.Equal(t, "client-id", claims.ClientId)
+	assert.Equal(t, "jobs:read jobs:write", claims.Scope)
+	assert.Empty(t, claims.EncryptedUserID)
+}
@@ -35,3 +35,27 @@ func ServeRequest(
 	router.ServeHTTP(w, req)
 	return w
 }
+
+// ServeRouteRequest works like ServeRequest but lets the registered route differ from the request
+// path (for path params), and runs a chain of handlers so tests can stand in for middleware.
+// w := ServeRouteRequest("DELETE", "/sessions/:id", "/sessions/3", []gin.HandlerFunc{setUser, h.DeleteSession}, nil)
+func ServeRouteRequest(
+	method, route, path string,
+	handlers []gin.HandlerFunc,
+	body interface{},
+) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+
+	router.Handle(method, route, handlers...)
+	req := httptest.NewRequest(method, path, nil)
+	if body != nil {
+		b, _ := json.Marshal(body)
+		req = httptest.NewRequest(method, path, bytes.NewBuffer(b))
+	}
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+	return w
+}
@@ -0,0 +1,164 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const forwardedForHeader = "X-Forwarded-For"
+
+// ClientIPResolver works out the real client address for requests that arrive through load
+// balancers or proxies.  Only addresses added by proxies we trust are believed, anything
+// further left in X-Forwarded-For could have been sent by the client itself.
+//
+// A proxy is trusted if its address is in one of the trusted CIDRs, or it's within `hops` of
+// the app.  Use hops when the proxies' addresses aren't known up front, e.g. on Cloud Run the
+// Google front end is one hop away.
+type ClientIPResolver struct {
+	trusted []netip.Prefix
+	hops    int
+	header  string
+}
+
+// NewClientIPResolver accepts CIDRs or bare IPs as trusted proxies.  An empty header disables
+// header lookups entirely, so only the connecting address is used.
+func NewClientIPResolver(trustedProxies []string, hops int, header string) (*ClientIPResolver, error) {
+	trusted := make([]netip.Prefix, 0, len(trustedProxies))
+	for _, proxy := range trustedProxies {
+		if !strings.Contains(proxy, "/") {
+			addr, err := netip.ParseAddr(proxy)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+			}
+			trusted = append(trusted, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		trusted = append(trusted, prefix.Masked())
+	}
+	if hops < 0 {
+		hops = 0
+	}
+	return &ClientIPResolver{
+		trusted: trusted,
+		hops:    hops,
+		header:  http.CanonicalHeaderKey(strings.TrimSpace(header)),
+	}, nil
+}
+
+// Resolve returns the client address for a request, falling back to the connecting address
+// whenever the headers can't be trusted or don't parse
+func (r *ClientIPResolver) Resolve(req *http.Request) string {
+	remote, ok := parseIP(req.RemoteAddr)
+	if !ok {
+		return req.RemoteAddr
+	}
+	if r.header == "" {
+		return remote.String()
+	}
+
+	if r.header != forwardedForHeader {
+		// Single value headers (X-Real-IP etc.) are only believed from a trusted peer
+		if !r.isTrusted(remote) && r.hops == 0 {
+			return remote.String()
+		}
+		if ip, ok := parseIP(req.Header.Get(r.header)); ok {
+			return ip.String()
+		}
+		return remote.String()
+	}
+
+	// Walk the chain right to left, i.e. from us back towards the client, stepping past each
+	// trusted proxy.  The first address we don't trust is the client.
+	chain := forwardedFor(req.Header.Values(forwardedForHeader))
+	chain = append(chain, remote.String())
+	hopsLeft := r.hops
+	i := len(chain) - 1
+	for i > 0 {
+		ip, _ := parseIP(chain[i])
+		if r.isTrusted(ip) {
+			i--
+			continue
+		}
+		if hopsLeft > 0 {
+			hopsLeft--
+			i--
+			continue
+		}
+		break
+	}
+
+	if ip, ok := parseIP(chain[i]); ok {
+		return ip.String()
+	}
+	// A proxy we trust passed on garbage, the closest address we can vouch for is the one after
+	// it.  The remote address always parses, so there is always one after it.
+	if ip, ok := parseIP(chain[i+1]); ok {
+		return ip.String()
+	}
+	return remote.String()
+}
+
+func (r *ClientIPResolver) isTrusted(ip netip.Addr) bool {
+	if !ip.IsValid() {
+		return false
+	}
+	for _, prefix := range r.trusted {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func forwardedFor(values []string) []string {
+	chain := []string{}
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if trimmed := strings.TrimSpace(part); trimmed != "" {
+				chain = append(chain, trimmed)
+			}
+		}
+	}
+	return chain
+}
+
+// parseIP accepts bare addresses or host:port, normalising IPv4-mapped IPv6 to IPv4
+func parseIP(value string) (netip.Addr, bool) {
+	value = strings.TrimSpace(value)
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		value = host
+	}
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+func WithClientIP(ctx context.Context, clientIP string) context.Context {
+	return context.WithValue(ctx, clientIPKey, clientIP)
+}
+
+func ClientIPFromContext(ctx context.Context) (string, bool) {
+	v, ok := value(ctx, clientIPKey).(string)
+	return v, ok
+}
+
+// GetClientIP returns the address resolved by `ClientIPMiddleware`, or gin's own guess if it
+// isn't installed
+func GetClientIP(c *gin.Context) string {
+	if clientIP, ok := ClientIPFromContext(c.Request.Context()); ok && clientIP != "" {
+		return clientIP
+	}
+	return c.ClientIP()
+}
@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func resolve(resolver *ClientIPResolver, remoteAddr string, headers map[string]string) string {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = remoteAddr
+	for k, v := range headers {
+		r.Header.Set(k, v)
+	}
+	return resolver.Resolve(r)
+}
+
+func TestNewClientIPResolver_invalidProxy_error(t *testing.T) {
+	_, err := NewClientIPResolver([]string{"not-an-ip"}, 0, "X-Forwarded-For")
+	assert.Error(t, err)
+
+	_, err = NewClientIPResolver([]string{"10.0.0.0/33"}, 0, "X-Forwarded-For")
+	assert.Error(t, err)
+}
+
+func TestClientIPResolver_Resolve_untrustedRemote_ignoresHeader(t *testing.T) {
+	resolver, _ := NewClientIPResolver([]string{"10.0.0.0/8"}, 0, "X-Forwarded-For")
+
+	result := resolve(resolver, "203.0.113.9:1234", map[string]string{
+		"X-Forwarded-For": "198.51.100.1",
+	})
+
+	assert.Equal(t, "203.0.113.9", result)
+}
+
+func TestClientIPResolver_Resolve_trustedCIDRs_success(t *testing.T) {
+	resolver, _ := NewClientIPResolver([]string{"10.0.0.0/8", "192.168.1.1"}, 0, "X-Forwarded-For")
+
+	result := resolve(resolver, "10.0.0.2:1234", map[string]string{
+		"X-Forwarded-For": "1.1.1.1, 198.51.100.1, 192.168.1.1, 10.0.0.5",
+	})
+
+	assert.Equal(t, "198.51.100.1", result)
+}
+
+func TestClientIPResolver_Resolve_hops_ignoresSpoofedEntries(t *testing.T) {
+	resolver, _ := NewClientIPResolver(nil, 1, "X-Forwarded-For")
+
+	result := resolve(resolver, "169.254.1.1:1234", map[string]string{
+		"X-Forwarded-For": "1.1.1.1, 198.51.100.1",
+	})
+
+	assert.Equal(t, "198.51.100.1", result)
+}
+
+func TestClientIPResolver_Resolve_hopsExceedChain_leftmost(t *testing.T) {
+	resolver, _ := NewClientIPResolver(nil, 5, "X-Forwarded-For")
+
+	result := resolve(resolver, "169.254.1.1:1234", map[string]string{
+		"X-Forwarded-For": "198.51.100.1",
+	})
+
+	assert.Equal(t, "198.51.100.1", result)
+}
+
+func TestClientIPResolver_Resolve_garbageFromTrustedProxy_fallsBack(t *testing.T) {
+	resolver, _ := NewClientIPResolver([]string{"10.0.0.0/8"}, 0, "X-Forwarded-For")
+
+	result := resolve(resolver, "10.0.0.2:1234", map[string]string{
+		"X-Forwarded-For": "unknown, 10.0.0.5",
+	})
+
+	assert.Equal(t, "10.0.0.5", result)
+}
+
+func TestClientIPResolver_Resolve_singleValueHeader(t *testing.T) {
+	resolver, _ := NewClientIPResolver([]string{"10.0.0.0/8"}, 0, "x-real-ip")
+
+	trusted := resolve(resolver, "10.0.0.2:1234", map[string]string{"X-Real-IP": "198.51.100.1"})
+	untrusted := resolve(resolver, "203.0.113.9:1234", map[string]string{"X-Real-IP": "198.51.100.1"})
+	invalid := resolve(resolver, "10.0.0.2:1234", map[string]string{"X-Real-IP": "nope"})
+
+	assert.Equal(t, "198.51.100.1", trusted)
+	assert.Equal(t, "203.0.113.9", untrusted)
+	assert.Equal(t, "10.0.0.2", invalid)
+}
+
+func TestClientIPResolver_Resolve_noHeader_remoteOnly(t *testing.T) {
+	resolver, _ := NewClientIPResolver([]string{"0.0.0.0/0"}, 0, "")
+
+	result := resolve(resolver, "10.0.0.2:1234", map[string]string{
+		"X-Forwarded-For": "198.51.100.1",
+	})
+
+	assert.Equal(t, "10.0.0.2", result)
+}
+
+func TestClientIPResolver_Resolve_ipv4MappedIPv6(t *testing.T) {
+	resolver, _ := NewClientIPResolver([]string{"10.0.0.0/8"}, 0, "X-Forwarded-For")
+
+	result := resolve(resolver, "[::ffff:10.0.0.2]:1234", map[string]string{
+		"X-Forwarded-For": "2001:db8::1",
+	})
+
+	assert.Equal(t, "2001:db8::1", result)
+}
+
+func TestGetClientIP(t *testing.T) {
+	var resolved, fallback string
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/resolved", func(c *gin.Context) {
+		c.Request = c.Request.WithContext(WithClientIP(c.Request.Context(), "198.51.100.1"))
+		resolved = GetClientIP(c)
+	})
+	router.GET("/fallback", func(c *gin.Context) {
+		fallback = GetClientIP(c)
+	})
+
+	r := httptest.NewRequest("GET", "/resolved", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	r = httptest.NewRequest("GET", "/fallback", nil)
+	r.RemoteAddr = "203.0.113.9:1234"
+	router.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.Equal(t, "198.51.100.1", resolved)
+	assert.Equal(t, "203.0.113.9", fallback)
+}
@@ -0,0 +1,157 @@
+package utils
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/Admiral-Piett/go-tools/gin/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// contextKey is unexported so no other package can collide with (or overwrite) our values
+type contextKey string
+
+const (
+	userIdKey       contextKey = "userId"
+	userIdStringKey contextKey = "userIdString"
+	deviceTokenKey  contextKey = "deviceToken"
+	claimsKey       contextKey = "claims"
+	requestIdKey    contextKey = "requestId"
+	rolesKey        contextKey = "roles"
+	scopesKey       contextKey = "scopes"
+	apiKeyPrefixKey contextKey = "apiKeyPrefix"
+	clientIdKey     contextKey = "clientId"
+	clientIPKey     contextKey = "clientIP"
+)
+
+// value looks up key, falling back to the bare string key it used to be stored under so values
+// set by code that hasn't migrated yet are still found.
+func value(ctx context.Context, key contextKey) interface{} {
+	if v := ctx.Value(key); v != nil {
+		return v
+	}
+	return ctx.Value(string(key))
+}
+
+// Setters - use these instead of `context.WithValue` so values land under the typed keys
+
+func WithUserId(ctx context.Context, userId int) context.Context {
+	return context.WithValue(ctx, userIdKey, userId)
+}
+
+func WithDeviceToken(ctx context.Context, deviceToken string) context.Context {
+	return context.WithValue(ctx, deviceTokenKey, deviceToken)
+}
+
+func WithClaims(ctx context.Context, claims *models.AuthClaims) context.Context {
+	return context.WithValue(ctx, claimsKey, claims)
+}
+
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdKey, requestId)
+}
+
+func WithRoles(ctx context.Context, roles []string) context.Context {
+	return context.WithValue(ctx, rolesKey, roles)
+}
+
+func WithScopes(ctx context.Context, scopes []string) context.Context {
+	return context.WithValue(ctx, scopesKey, scopes)
+}
+
+func WithAPIKeyPrefix(ctx context.Context, prefix string) context.Context {
+	return context.WithValue(ctx, apiKeyPrefixKey, prefix)
+}
+
+func WithClientId(ctx context.Context, clientId string) context.Context {
+	return context.WithValue(ctx, clientIdKey, clientId)
+}
+
+// WithLegacyAuthValues also stores the user id and device token under the bare "userId" and
+// "deviceToken" keys RequireAuth used before the typed ones, so app code still calling
+// `ctx.Value("userId")` keeps working during migration.  Int ids are stored as ints, as they
+// were.
+//
+// Deprecated: read them with GetUserId, GetUserIdString and GetDeviceToken, the bare keys will
+// be dropped once apps have moved over.
+func WithLegacyAuthValues(ctx context.Context, userId string, deviceToken string) context.Context {
+	var legacyUserId interface{} = userId
+	if i, err := strconv.Atoi(userId); err == nil {
+		legacyUserId = i
+	}
+	ctx = context.WithValue(ctx, string(userIdKey), legacyUserId)
+	return context.WithValue(ctx, string(deviceTokenKey), deviceToken)
+}
+
+// Accessors for plain contexts, e.g. in service layers that are handed `c.Request.Context()`
+
+func UserIdFromContext(ctx context.Context) (int, bool) {
+	v, ok := value(ctx, userIdKey).(int)
+	return v, ok
+}
+
+func DeviceTokenFromContext(ctx context.Context) (string, bool) {
+	v, ok := value(ctx, deviceTokenKey).(string)
+	return v, ok
+}
+
+func ClaimsFromContext(ctx context.Context) (*models.AuthClaims, bool) {
+	v, ok := value(ctx, claimsKey).(*models.AuthClaims)
+	return v, ok && v != nil
+}
+
+func RequestIdFromContext(ctx context.Context) (string, bool) {
+	v, ok := value(ctx, requestIdKey).(string)
+	return v, ok
+}
+
+func RolesFromContext(ctx context.Context) ([]string, bool) {
+	v, ok := value(ctx, rolesKey).([]string)
+	return v, ok
+}
+
+func ScopesFromContext(ctx context.Context) ([]string, bool) {
+	v, ok := value(ctx, scopesKey).([]string)
+	return v, ok
+}
+
+func APIKeyPrefixFromContext(ctx context.Context) (string, bool) {
+	v, ok := value(ctx, apiKeyPrefixKey).(string)
+	return v, ok
+}
+
+func ClientIdFromContext(ctx context.Context) (string, bool) {
+	v, ok := value(ctx, clientIdKey).(string)
+	return v, ok
+}
+
+// Accessors for handlers
+
+func GetDeviceToken(c *gin.Context) (string, bool) {
+	return DeviceTokenFromContext(c.Request.Context())
+}
+
+func GetClaims(c *gin.Context) (*models.AuthClaims, bool) {
+	return ClaimsFromContext(c.Request.Context())
+}
+
+func GetRequestId(c *gin.Context) (string, bool) {
+	return RequestIdFromContext(c.Request.Context())
+}
+
+func GetRoles(c *gin.Context) ([]string, bool) {
+	return RolesFromContext(c.Request.Context())
+}
+
+func GetScopes(c *gin.Context) ([]string, bool) {
+	return ScopesFromContext(c.Request.Context())
+}
+
+func GetAPIKeyPrefix(c *gin.Context) (string, bool) {
+	return APIKeyPrefixFromContext(c.Request.Context())
+}
+
+func GetClientId(c *gin.Context) (string, bool) {
+	return ClientIdFromContext(c.Request.Context())
+}
@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Admiral-Piett/go-tools/gin/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextAccessors_success(t *testing.T) {
+	claims := &models.AuthClaims{EncryptedUserID: "encrypted-user-id"}
+	ctx := WithUserId(context.Background(), 1)
+	ctx = WithDeviceToken(ctx, "device-token")
+	ctx = WithClaims(ctx, claims)
+	ctx = WithRequestId(ctx, "request-id")
+	ctx = WithRoles(ctx, []string{"admin"})
+	ctx = WithScopes(ctx, []string{"read"})
+	ctx = WithAPIKeyPrefix(ctx, "sk_abc")
+	ctx = WithClientId(ctx, "client-id")
+
+	userId, ok := UserIdFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, 1, userId)
+
+	deviceToken, ok := DeviceTokenFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "device-token", deviceToken)
+
+	resultClaims, ok := ClaimsFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, claims, resultClaims)
+
+	requestId, ok := RequestIdFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "request-id", requestId)
+
+	roles, ok := RolesFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"admin"}, roles)
+
+	scopes, ok := ScopesFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"read"}, scopes)
+
+	prefix, ok := APIKeyPrefixFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "sk_abc", prefix)
+
+	clientId, ok := ClientIdFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "client-id", clientId)
+}
+
+func TestContextAccessors_missing_failure(t *testing.T) {
+	ctx := context.Background()
+
+	_, ok := UserIdFromContext(ctx)
+	assert.False(t, ok)
+	_, ok = DeviceTokenFromContext(ctx)
+	assert.False(t, ok)
+	_, ok = ClaimsFromContext(ctx)
+	assert.False(t, ok)
+	_, ok = RequestIdFromContext(ctx)
+	assert.False(t, ok)
+	_, ok = RolesFromContext(ctx)
+	assert.False(t, ok)
+	_, ok = ScopesFromContext(ctx)
+	assert.False(t, ok)
+	_, ok = APIKeyPrefixFromContext(ctx)
+	assert.False(t, ok)
+}
+
+func TestContextAccessors_legacyStringKeys_success(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "userId", 1)
+	ctx = context.WithValue(ctx, "deviceToken", "device-token")
+
+	userId, ok := UserIdFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, 1, userId)
+
+	deviceToken, ok := DeviceTokenFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "device-token", deviceToken)
+}
+
+func TestWithLegacyAuthValues(t *testing.T) {
+	ctx := WithLegacyAuthValues(context.Background(), "7", "device-token")
+	stringCtx := WithLegacyAuthValues(context.Background(), "2b1c3f9e", "")
+
+	assert.Equal(t, 7, ctx.Value("userId"))
+	assert.Equal(t, "device-token", ctx.Value("deviceToken"))
+	assert.Equal(t, "2b1c3f9e", stringCtx.Value("userId"))
+}
+
+func TestContextAccessors_typedKeyWinsOverLegacy(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "userId", 1)
+	ctx = WithUserId(ctx, 2)
+
+	userId, ok := UserIdFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, 2, userId)
+}
+
+func TestGinAccessors_success(t *testing.T) {
+	r := httptest.NewRequest("POST", "/temp", nil)
+	ctx := WithUserId(r.Context(), 1)
+	ctx = WithDeviceToken(ctx, "device-token")
+	ctx = WithRoles(ctx, []string{"admin"})
+	ctx = WithRequestId(ctx, "request-id")
+	c := &gin.Context{Request: r.WithContext(ctx)}
+
+	userId, ok := GetUserId(c)
+	assert.True(t, ok)
+	assert.Equal(t, 1, userId)
+
+	deviceToken, ok := GetDeviceToken(c)
+	assert.True(t, ok)
+	assert.Equal(t, "device-token", deviceToken)
+
+	roles, ok := GetRoles(c)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"admin"}, roles)
+
+	requestId, ok := GetRequestId(c)
+	assert.True(t, ok)
+	assert.Equal(t, "request-id", requestId)
+
+	_, ok = GetClaims(c)
+	assert.False(t, ok)
+}
@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"github.com/Admiral-Piett/go-tools/gin/interfaces"
+	"github.com/Admiral-Piett/go-tools/gin/models"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// ValidateRefreshTokenClaims validates a refresh token, returning its claims when tokenService
+// implements `interfaces.RefreshClaimsValidator`.  Otherwise only `Id`, the encrypted user id,
+// is filled in, and callers fall back to the user's current device.
+func ValidateRefreshTokenClaims(
+	tokenService interfaces.TokenServiceInterface,
+	tokenString string,
+) (*models.RefreshClaims, error) {
+	if v, ok := tokenService.(interfaces.RefreshClaimsValidator); ok {
+		return v.ValidateRefreshTokenClaims(tokenString)
+	}
+	encryptedUserID, err := tokenService.ValidateRefreshToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	return &models.RefreshClaims{StandardClaims: jwt.StandardClaims{Id: encryptedUserID}}, nil
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/Admiral-Piett/go-tools/gin/interfaces"
+	"github.com/Admiral-Piett/go-tools/gin/mocks"
+	"github.com/Admiral-Piett/go-tools/gin/models"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRefreshTokenClaims_claimsValidator(t *testing.T) {
+	tok := &mocks.MockTokenService{}
+	tok.MockValidateRefreshTokenClaims = func(tokenString string) (*models.RefreshClaims, error) {
+		return &models.RefreshClaims{
+			DeviceToken:    "phone",
+			StandardClaims: jwt.StandardClaims{Id: "encrypted-user-id"},
+		}, nil
+	}
+
+	claims, err := ValidateRefreshTokenClaims(tok, "refresh")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "encrypted-user-id", claims.Id)
+	assert.Equal(t, "phone", claims.DeviceToken)
+	assert.Equal(t, []interface{}{"refresh"}, tok.ValidateRefreshTokenClaimsCalledWith)
+}
+
+func TestValidateRefreshTokenClaims_legacyTokenService(t *testing.T) {
+	tok := &mocks.MockTokenService{}
+	tok.MockValidateRefreshToken = func(tokenString string) (string, error) {
+		return "encrypted-user-id", nil
+	}
+	// Only the original TokenServiceInterface methods
+	legacy := struct {
+		interfaces.TokenServiceInterface
+	}{tok}
+
+	claims, err := ValidateRefreshTokenClaims(legacy, "refresh")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "encrypted-user-id", claims.Id)
+	assert.Equal(t, "", claims.DeviceToken)
+	assert.Equal(t, []interface{}{"refresh"}, tok.ValidateRefreshTokenCalledWith)
+	assert.Nil(t, tok.ValidateRefreshTokenClaimsCalledWith)
+}
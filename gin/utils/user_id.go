@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/Admiral-Piett/go-tools/gin/interfaces"
+
+	"github.com/gin-gonic/gin"
+)
+
+// User ids travel through tokens and request contexts as strings, so any primary key type
+// works.  These helpers convert between the string form and the app's own id type.
+
+// EncodeUserId returns the string form of a user id
+func EncodeUserId[T interfaces.UserIdType](id T) string {
+	return fmt.Sprint(id)
+}
+
+// ParseUserId converts the string form of a user id back into the app's id type, e.g.
+//
+//	id, err := utils.ParseUserId[int64](idString)
+func ParseUserId[T interfaces.UserIdType](id string) (T, error) {
+	var result T
+	v := reflect.ValueOf(&result).Elem()
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(id)
+	case reflect.Int, reflect.Int64, reflect.Int32:
+		i, err := strconv.ParseInt(id, 10, v.Type().Bits())
+		if err != nil {
+			return result, err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint64, reflect.Uint32:
+		u, err := strconv.ParseUint(id, 10, v.Type().Bits())
+		if err != nil {
+			return result, err
+		}
+		v.SetUint(u)
+	}
+	return result, nil
+}
+
+// WithUserIdString stores the string form of the user id, and the int form too if it is one, so
+// GetUserId keeps working for int id apps.
+func WithUserIdString(ctx context.Context, userId string) context.Context {
+	ctx = context.WithValue(ctx, userIdStringKey, userId)
+	if i, err := strconv.Atoi(userId); err == nil {
+		ctx = WithUserId(ctx, i)
+	}
+	return ctx
+}
+
+// UserIdStringFromContext returns the user id as a string, whatever its original type
+func UserIdStringFromContext(ctx context.Context) (string, bool) {
+	if v, ok := value(ctx, userIdStringKey).(string); ok {
+		return v, true
+	}
+	// Contexts populated with only the int form
+	if v, ok := UserIdFromContext(ctx); ok {
+		return strconv.Itoa(v), true
+	}
+	return "", false
+}
+
+// UserIdFromContextAs returns the user id converted to the app's id type
+func UserIdFromContextAs[T interfaces.UserIdType](ctx context.Context) (T, bool) {
+	var zero T
+	v, ok := UserIdStringFromContext(ctx)
+	if !ok {
+		return zero, false
+	}
+	id, err := ParseUserId[T](v)
+	if err != nil {
+		return zero, false
+	}
+	return id, true
+}
+
+func GetUserIdString(c *gin.Context) (string, bool) {
+	return UserIdStringFromContext(c.Request.Context())
+}
+
+// GetUserIdAs is the generic form of GetUserId, e.g. `utils.GetUserIdAs[int64](c)`
+func GetUserIdAs[T interfaces.UserIdType](c *gin.Context) (T, bool) {
+	return UserIdFromContextAs[T](c.Request.Context())
+}
+
+// DecryptUserIdString decrypts a token's user id to its string form, using
+// `DecryptUserIDString` when tokenService supports string ids and `DecryptUserID` otherwise
+func DecryptUserIdString(
+	tokenService interfaces.TokenServiceInterface,
+	encryptedUserID string,
+) (string, error) {
+	if s, ok := tokenService.(interfaces.StringUserTokenServiceInterface); ok {
+		return s.DecryptUserIDString(encryptedUserID)
+	}
+	userId, err := tokenService.DecryptUserID(encryptedUserID)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(userId), nil
+}
@@ -0,0 +1,142 @@
+package utils
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Admiral-Piett/go-tools/gin/interfaces"
+	"github.com/Admiral-Piett/go-tools/gin/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type customId int64
+
+func TestEncodeUserId(t *testing.T) {
+	assert.Equal(t, "7", EncodeUserId(7))
+	assert.Equal(t, "7", EncodeUserId(uint64(7)))
+	assert.Equal(t, "7", EncodeUserId(customId(7)))
+	assert.Equal(t, "abc", EncodeUserId("abc"))
+}
+
+func TestParseUserId_success(t *testing.T) {
+	i, err := ParseUserId[int]("7")
+	assert.Nil(t, err)
+	assert.Equal(t, 7, i)
+
+	u, err := ParseUserId[uint32]("7")
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(7), u)
+
+	c, err := ParseUserId[customId]("7")
+	assert.Nil(t, err)
+	assert.Equal(t, customId(7), c)
+
+	s, err := ParseUserId[string]("2b1c3f9e-5d4a-4e0b-9a57-6f1d2c3b4a5e")
+	assert.Nil(t, err)
+	assert.Equal(t, "2b1c3f9e-5d4a-4e0b-9a57-6f1d2c3b4a5e", s)
+}
+
+func TestParseUserId_invalid_error(t *testing.T) {
+	_, err := ParseUserId[int]("garbage")
+	assert.Error(t, err)
+
+	_, err = ParseUserId[uint]("-1")
+	assert.Error(t, err)
+
+	_, err = ParseUserId[int32]("99999999999")
+	assert.Error(t, err)
+}
+
+func TestWithUserIdString_intId(t *testing.T) {
+	ctx := WithUserIdString(context.Background(), "7")
+
+	s, ok := UserIdStringFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "7", s)
+
+	i, ok := UserIdFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, 7, i)
+}
+
+func TestWithUserIdString_uuid(t *testing.T) {
+	ctx := WithUserIdString(context.Background(), "2b1c3f9e-5d4a-4e0b-9a57-6f1d2c3b4a5e")
+
+	s, ok := UserIdStringFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "2b1c3f9e-5d4a-4e0b-9a57-6f1d2c3b4a5e", s)
+
+	_, ok = UserIdFromContext(ctx)
+	assert.False(t, ok)
+}
+
+func TestUserIdStringFromContext_intOnly(t *testing.T) {
+	ctx := WithUserId(context.Background(), 7)
+
+	s, ok := UserIdStringFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "7", s)
+}
+
+func TestGetUserIdAs_success(t *testing.T) {
+	r := httptest.NewRequest("POST", "/temp", nil)
+	c := &gin.Context{Request: r.WithContext(WithUserIdString(r.Context(), "7"))}
+
+	id, ok := GetUserIdAs[int64](c)
+	assert.True(t, ok)
+	assert.Equal(t, int64(7), id)
+
+	s, ok := GetUserIdString(c)
+	assert.True(t, ok)
+	assert.Equal(t, "7", s)
+}
+
+func TestGetUserIdAs_wrongType_failure(t *testing.T) {
+	r := httptest.NewRequest("POST", "/temp", nil)
+	c := &gin.Context{Request: r.WithContext(WithUserIdString(r.Context(), "abc"))}
+
+	id, ok := GetUserIdAs[int64](c)
+	assert.False(t, ok)
+	assert.Equal(t, int64(0), id)
+}
+
+func TestGetUserIdAs_missing_failure(t *testing.T) {
+	r := httptest.NewRequest("POST", "/temp", nil)
+	c := &gin.Context{Request: r}
+
+	_, ok := GetUserIdAs[string](c)
+	assert.False(t, ok)
+}
+
+func TestDecryptUserIdString_stringTokenService(t *testing.T) {
+	tok := &mocks.MockTokenService{}
+	tok.MockDecryptUserIDString = func(encryptedUserID string) (string, error) {
+		return "abc", nil
+	}
+
+	userId, err := DecryptUserIdString(tok, "encrypted")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "abc", userId)
+	assert.Nil(t, tok.DecryptUserIDCalledWith)
+}
+
+func TestDecryptUserIdString_intTokenService(t *testing.T) {
+	tok := &mocks.MockTokenService{}
+	tok.MockDecryptUserID = func(encryptedUserID string) (int, error) {
+		return 7, nil
+	}
+	// Only exposes TokenServiceInterface, like token services written before string ids
+	intOnly := struct {
+		interfaces.TokenServiceInterface
+	}{tok}
+
+	userId, err := DecryptUserIdString(intOnly, "encrypted")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "7", userId)
+	assert.Nil(t, tok.DecryptUserIDStringCalledWith)
+}
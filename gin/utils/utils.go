@@ -7,7 +7,7 @@ import (
 )
 
 func GetUserId(c *gin.Context) (int, bool) {
-	v := c.Request.Context().Value("userId")
+	v := value(c.Request.Context(), userIdKey)
 	if v == nil {
 		return 0, false
 	}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"github.com/Admiral-Piett/go-tools/gorm/interfaces"
+	"github.com/Admiral-Piett/go-tools/logging"
 	"github.com/Admiral-Piett/go-tools/settings"
 	"strings"
 
@@ -11,7 +12,6 @@ import (
 	log "github.com/sirupsen/logrus"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
 // NOTE: You're not really going to be able to unit test this layer.  Leave it for smoke tests.
@@ -25,18 +25,11 @@ type Database struct {
 func NewDatabase(cfg *settings.BaseSettings) (interfaces.DatabaseInterface, error) {
 	log.Info("Connecting to database")
 
-	// Configure GORM logger based on environment
-	var gormLogger logger.Interface
-	switch strings.ToUpper(cfg.SqlLogLevel) {
-	case "INFO":
-		gormLogger = logger.Default.LogMode(logger.Info)
-	case "WARNING":
-		gormLogger = logger.Default.LogMode(logger.Warn)
-	case "ERROR":
-		gormLogger = logger.Default.LogMode(logger.Error)
-	default:
-		gormLogger = logger.Default.LogMode(logger.Silent)
-	}
+	// Write GORM's logs through logrus so they match the rest of the JSON log stream
+	gormLogger := NewGormLogger(
+		gormLogLevel(cfg.SqlLogLevel),
+		logging.NewDefaultRedactor(cfg.EncryptionKey, cfg.JwtHmacKey, cfg.ApiKeyHmacKey, cfg.RequestSigningKey),
+	)
 
 	var db *gorm.DB
 	var err error
@@ -0,0 +1,189 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/Admiral-Piett/go-tools/logging"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// SQL log fields
+var (
+	SQL            = "sql"
+	ROWS           = "rows"
+	DURATION_MS    = "duration_ms"
+	SLOW_THRESHOLD = "slow_threshold_ms"
+	SQL_CALLER     = "sql_caller"
+)
+
+const defaultSlowQuery = 200 * time.Millisecond
+
+// GormLogger writes GORM's SQL, error and slow query logs as structured logrus entries, so
+// they come out in the same format as everything else.  Entries carry the request's log fields
+// when queries are run with a context, e.g. `db.DB().WithContext(c.Request.Context())`.
+//
+// Query parameters are left out by default, the SQL is logged with its placeholders.  Set
+// LogParams to include them, with strings masked since they're where password hashes, API key
+// HMACs and client secret hashes end up.  LogStringParams includes strings too, passed through
+// Redactor, which only catches secrets by their shape, so leave it off where those are stored.
+type GormLogger struct {
+	Level                     logger.LogLevel
+	SlowThreshold             time.Duration
+	IgnoreRecordNotFoundError bool
+	LogParams                 bool
+	LogStringParams           bool
+	Redactor                  *logging.Redactor
+}
+
+// NewGormLogger logs at level (logger.Silent to logger.Info), flagging queries slower than
+// 200ms and ignoring record not found errors, which are usually expected
+func NewGormLogger(level logger.LogLevel, redactor *logging.Redactor) *GormLogger {
+	return &GormLogger{
+		Level:                     level,
+		SlowThreshold:             defaultSlowQuery,
+		IgnoreRecordNotFoundError: true,
+		Redactor:                  redactor,
+	}
+}
+
+// LogMode implements logger.Interface
+func (l *GormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	copied := *l
+	copied.Level = level
+	return &copied
+}
+
+// Info implements logger.Interface
+func (l *GormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.Level >= logger.Info {
+		l.entry(ctx).Infof(msg, data...)
+	}
+}
+
+// Warn implements logger.Interface
+func (l *GormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.Level >= logger.Warn {
+		l.entry(ctx).Warnf(msg, data...)
+	}
+}
+
+// Error implements logger.Interface
+func (l *GormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.Level >= logger.Error {
+		l.entry(ctx).Errorf(msg, data...)
+	}
+}
+
+// Trace implements logger.Interface, logging failed and slow queries and, at logger.Info,
+// every query
+func (l *GormLogger) Trace(
+	ctx context.Context,
+	begin time.Time,
+	fc func() (string, int64),
+	err error,
+) {
+	if l.Level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	failed := err != nil && l.Level >= logger.Error &&
+		!(l.IgnoreRecordNotFoundError && errors.Is(err, gorm.ErrRecordNotFound))
+	slow := l.SlowThreshold != 0 && elapsed > l.SlowThreshold && l.Level >= logger.Warn
+	if !failed && !slow && l.Level < logger.Info {
+		return
+	}
+
+	sql, rows := fc()
+	fields := log.Fields{
+		SQL:         sql,
+		DURATION_MS: float64(elapsed.Nanoseconds()) / 1e6,
+	}
+	// -1 means the statement doesn't report affected rows
+	if rows >= 0 {
+		fields[ROWS] = rows
+	}
+	entry := l.entry(ctx).WithFields(fields)
+
+	switch {
+	case failed:
+		entry.WithError(err).Error("SQL Query Failure")
+	case slow:
+		entry.WithField(SLOW_THRESHOLD, l.SlowThreshold.Milliseconds()).Warn("Slow SQL Query")
+	default:
+		entry.Info("SQL Query")
+	}
+}
+
+// ParamsFilter implements gorm.ParamsFilter, deciding which parameters make it into the SQL
+// passed to Trace
+func (l *GormLogger) ParamsFilter(
+	ctx context.Context,
+	sql string,
+	params ...interface{},
+) (string, []interface{}) {
+	if !l.LogParams {
+		return sql, nil
+	}
+	filtered := make([]interface{}, len(params))
+	for i, param := range params {
+		switch p := param.(type) {
+		case string:
+			if l.LogStringParams {
+				filtered[i] = l.Redactor.RedactString(p)
+			} else {
+				filtered[i] = logging.Redacted
+			}
+		case []byte:
+			filtered[i] = fmt.Sprintf("<%d bytes>", len(p))
+		default:
+			filtered[i] = param
+		}
+	}
+	return sql, filtered
+}
+
+// entry carries the context's fields and where the query came from, since the `function`
+// field only ever points at this logger
+func (l *GormLogger) entry(ctx context.Context) *log.Entry {
+	return logging.FromContext(ctx).WithField(SQL_CALLER, queryCaller())
+}
+
+// queryCaller finds the first frame outside GORM and this logger, i.e. the code running the query
+func queryCaller() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "gorm.io/") &&
+			!strings.Contains(frame.Function, "(*GormLogger)") {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// gormLogLevel maps SQL_LOG_LEVEL onto GORM's levels, anything unknown silences it
+func gormLogLevel(level string) logger.LogLevel {
+	switch strings.ToUpper(level) {
+	case "INFO":
+		return logger.Info
+	case "WARN", "WARNING":
+		return logger.Warn
+	case "ERROR":
+		return logger.Error
+	default:
+		return logger.Silent
+	}
+}
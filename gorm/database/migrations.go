@@ -1,8 +1,10 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"github.com/Admiral-Piett/go-tools/gorm/interfaces"
+	"github.com/Admiral-Piett/go-tools/logging"
 	"sort"
 	"time"
 
@@ -42,7 +44,15 @@ func RegisterMigration(migration Migration) {
 }
 
 // RunMigrations executes all pending migrations
-func RunMigrations(db interfaces.DatabaseInterface) (err error) {
+func RunMigrations(db interfaces.DatabaseInterface) error {
+	return RunMigrationsContext(context.Background(), db)
+}
+
+// RunMigrationsContext is RunMigrations with the log fields from ctx (see `logging.FromContext`)
+// on every line, e.g. to tie migration logs to a deploy or admin request
+func RunMigrationsContext(ctx context.Context, db interfaces.DatabaseInterface) (err error) {
+	logger := logging.FromContext(ctx)
+
 	// Create migrations table if it doesn't exist
 	if err := db.AutoMigrate(&MigrationRecord{}); err != nil {
 		return fmt.Errorf("failed to create migrations table: %w", err)
@@ -58,14 +68,14 @@ func RunMigrations(db interfaces.DatabaseInterface) (err error) {
 	var appliedMigrations []string
 	defer func() {
 		if err != nil {
-			log.WithFields(log.Fields{
+			logger.WithFields(log.Fields{
 				MIGRATION_ID: currentMigrationId,
 				ERROR:        err,
 			}).Error("Migration failed, rolling back")
 
 			// Attempt to rollback all migrations applied in this session
-			if rollbackErr := rollbackMigrations(db, appliedMigrations); rollbackErr != nil {
-				log.WithError(rollbackErr).
+			if rollbackErr := rollbackMigrations(logger, db, appliedMigrations); rollbackErr != nil {
+				logger.WithError(rollbackErr).
 					Error("Failed to rollback migrations - database may be in inconsistent state")
 				err = fmt.Errorf(
 					"migration %s failed and rollback failed: %w (rollback error: %v)",
@@ -92,13 +102,13 @@ func RunMigrations(db interfaces.DatabaseInterface) (err error) {
 		db.Model(&MigrationRecord{}).Where("id = ?", migration.Id).Count(&count)
 
 		if count == 0 {
-			log.Info(
+			logger.Info(
 				fmt.Sprintf(
 					"Running database migration %s",
 					currentMigrationId,
 				),
 			)
-			log.WithFields(log.Fields{
+			logger.WithFields(log.Fields{
 				MIGRATION_ID:          migration.Id,
 				MIGRATION_DESCRIPTION: migration.Description,
 			}).Info("Running migration")
@@ -131,8 +141,12 @@ func RunMigrations(db interfaces.DatabaseInterface) (err error) {
 }
 
 // rollbackMigrations rolls back a list of migrations that have already been preformed today in reverse order
-func rollbackMigrations(db interfaces.DatabaseInterface, appliedMigrations []string) error {
-	log.WithField(MIGRATION_IDS_TO_ROLLBACK, len(appliedMigrations)).
+func rollbackMigrations(
+	logger *log.Entry,
+	db interfaces.DatabaseInterface,
+	appliedMigrations []string,
+) error {
+	logger.WithField(MIGRATION_IDS_TO_ROLLBACK, len(appliedMigrations)).
 		Warn("Rolling back migrations")
 
 	// Rollback in reverse order
@@ -149,12 +163,12 @@ func rollbackMigrations(db interfaces.DatabaseInterface, appliedMigrations []str
 		}
 
 		if migration == nil {
-			log.WithField(MIGRATION_ID, migrationID).
+			logger.WithField(MIGRATION_ID, migrationID).
 				Error("Migration not found in registry for rollback")
 			continue
 		}
 
-		log.WithFields(log.Fields{
+		logger.WithFields(log.Fields{
 			MIGRATION_ID:          migration.Id,
 			MIGRATION_DESCRIPTION: migration.Description,
 		}).Info("Rolling back migration")
@@ -171,7 +185,7 @@ func rollbackMigrations(db interfaces.DatabaseInterface, appliedMigrations []str
 				Error
 		})
 		if err != nil {
-			log.WithFields(log.Fields{
+			logger.WithFields(log.Fields{
 				MIGRATION_ID: migration.Id,
 				ERROR:        err,
 			}).Error("Failed to rollback migration")
@@ -183,6 +197,6 @@ func rollbackMigrations(db interfaces.DatabaseInterface, appliedMigrations []str
 		}
 	}
 
-	log.Info("Migration rollback completed")
+	logger.Info("Migration rollback completed")
 	return nil
 }
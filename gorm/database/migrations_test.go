@@ -1,10 +1,15 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"testing"
 
+	"github.com/Admiral-Piett/go-tools/logging"
+	"github.com/Admiral-Piett/go-tools/logging/testlog"
+
+	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -294,3 +299,32 @@ func TestRunMigrations_Transaction_Multi_failure_WithInCodeRollback(
 	err = mock.ExpectationsWereMet()
 	assert.Nil(t, err)
 }
+
+func TestRunMigrationsContext_logsContextFields(t *testing.T) {
+	defer func() {
+		MigrationRegistry = []Migration{}
+	}()
+	RegisterMigration(Migration{
+		Id:          "001_test_migration",
+		Description: "Test Migration 1",
+		Up:          func(*gorm.DB) error { return nil },
+		Down:        func(*gorm.DB) error { return nil },
+	})
+
+	logs := testlog.Capture(t)
+
+	d, err := NewInMemoryDatabase(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	ctx := logging.WithField(context.Background(), "deploy_id", "deploy-1")
+
+	err = RunMigrationsContext(ctx, d)
+
+	assert.Nil(t, err)
+	logs.AssertLogged(t, log.InfoLevel, "Running migration", log.Fields{
+		"migration_id": "001_test_migration",
+		"deploy_id":    "deploy-1",
+	})
+}
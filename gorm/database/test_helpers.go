@@ -1,10 +1,15 @@
 package database
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"testing"
 	"time"
 
+	"github.com/Admiral-Piett/go-tools/gorm/interfaces"
+	"github.com/Admiral-Piett/go-tools/settings"
+
 	"github.com/DATA-DOG/go-sqlmock"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -22,6 +27,33 @@ func NewTestableDatabase() (*Database, sqlmock.Sqlmock) {
 	return d, mock
 }
 
+// NewInMemoryDatabase returns a real, isolated sqlite database for tests that need working SQL
+// rather than sqlmock expectations.  Use a unique name per test, e.g. `t.Name()`.
+func NewInMemoryDatabase(name string) (interfaces.DatabaseInterface, error) {
+	return NewDatabase(&settings.BaseSettings{
+		SqlType:     "sqlite",
+		SqlUri:      fmt.Sprintf("file:%s?mode=memory&cache=shared", name),
+		SqlLogLevel: "ERROR",
+	})
+}
+
+// NewTestDatabase is an in memory database for t with migrations applied, closed when t
+// finishes
+func NewTestDatabase(t testing.TB, migrations ...Migration) interfaces.DatabaseInterface {
+	t.Helper()
+	db, err := NewInMemoryDatabase(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	for _, migration := range migrations {
+		if err := migration.Up(db.DB()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
 func newGormDBMock() (*gorm.DB, sqlmock.Sqlmock) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
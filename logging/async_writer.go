@@ -0,0 +1,155 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// DropPolicy decides what an AsyncWriter does with a line when its queue is full
+type DropPolicy int
+
+const (
+	// DropNewest discards the line being written, keeping what's already queued
+	DropNewest DropPolicy = iota
+	// DropOldest discards the oldest queued line to make room, keeping the most recent output
+	DropOldest
+	// Block waits for room, never losing a line but slowing callers down like a synchronous writer
+	Block
+)
+
+// AsyncWriter moves log writes off the request path: Write copies the line onto a bounded
+// queue and returns, a single goroutine writes the queue to out.  When the queue is full lines
+// are dropped (or callers block) according to the DropPolicy, and counted.
+//
+// Flush or Close it before exiting or the tail of the logs is lost, see `logging.Close`.
+type AsyncWriter struct {
+	out    io.Writer
+	queue  chan []byte
+	policy DropPolicy
+
+	dropped atomic.Uint64
+
+	// pending counts lines accepted but not yet written or dropped, for Flush
+	pendingMu sync.Mutex
+	pending   int
+	drained   *sync.Cond
+
+	// closeMu guards closed and stops Close closing the queue under a Write
+	closeMu sync.RWMutex
+	closed  bool
+	done    chan struct{}
+	outMu   sync.Mutex
+}
+
+func NewAsyncWriter(out io.Writer, queueSize int, policy DropPolicy) *AsyncWriter {
+	w := &AsyncWriter{
+		out:    out,
+		queue:  make(chan []byte, queueSize),
+		policy: policy,
+		done:   make(chan struct{}),
+	}
+	w.drained = sync.NewCond(&w.pendingMu)
+	go w.run()
+	return w
+}
+
+// Write implements io.Writer.  It never fails, lines that can't be queued are dropped.  After
+// Close it writes straight through.
+func (w *AsyncWriter) Write(p []byte) (int, error) {
+	w.closeMu.RLock()
+	defer w.closeMu.RUnlock()
+	if w.closed {
+		w.outMu.Lock()
+		defer w.outMu.Unlock()
+		return w.out.Write(p)
+	}
+
+	// The caller may reuse p, e.g. logrus' pooled buffers
+	line := make([]byte, len(p))
+	copy(line, p)
+	w.addPending(1)
+
+	switch w.policy {
+	case Block:
+		w.queue <- line
+	case DropOldest:
+		for {
+			select {
+			case w.queue <- line:
+				return len(p), nil
+			default:
+			}
+			select {
+			case <-w.queue:
+				w.dropped.Add(1)
+				w.addPending(-1)
+			default:
+			}
+		}
+	default:
+		select {
+		case w.queue <- line:
+		default:
+			w.dropped.Add(1)
+			w.addPending(-1)
+		}
+	}
+	return len(p), nil
+}
+
+// Dropped returns how many lines have been discarded because the queue was full
+func (w *AsyncWriter) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+// Flush waits for every line written so far to reach out
+func (w *AsyncWriter) Flush() {
+	w.pendingMu.Lock()
+	defer w.pendingMu.Unlock()
+	for w.pending > 0 {
+		w.drained.Wait()
+	}
+}
+
+// Close writes out what's queued and stops the writer goroutine.  Later writes go straight to
+// out.  Reports how many lines were dropped over the writer's life, if any.
+func (w *AsyncWriter) Close() error {
+	w.closeMu.Lock()
+	if w.closed {
+		w.closeMu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.queue)
+	w.closeMu.Unlock()
+	<-w.done
+
+	if dropped := w.Dropped(); dropped > 0 {
+		fmt.Fprintf(os.Stderr, "Async log writer dropped %d lines\n", dropped)
+	}
+	return nil
+}
+
+func (w *AsyncWriter) run() {
+	defer close(w.done)
+	for line := range w.queue {
+		w.outMu.Lock()
+		if _, err := w.out.Write(line); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write to log, %v\n", err)
+		}
+		w.outMu.Unlock()
+		w.addPending(-1)
+	}
+}
+
+func (w *AsyncWriter) addPending(delta int) {
+	w.pendingMu.Lock()
+	defer w.pendingMu.Unlock()
+	w.pending += delta
+	if w.pending <= 0 {
+		w.drained.Broadcast()
+	}
+}
@@ -0,0 +1,131 @@
+package logging
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// gatedWriter blocks every write until release is closed, signalling started on the first one
+type gatedWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	started chan struct{}
+	once    sync.Once
+	release chan struct{}
+}
+
+func newGatedWriter() *gatedWriter {
+	return &gatedWriter{started: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (g *gatedWriter) Write(p []byte) (int, error) {
+	g.once.Do(func() { close(g.started) })
+	<-g.release
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.buf.Write(p)
+}
+
+func (g *gatedWriter) String() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.buf.String()
+}
+
+// fillQueue writes "1" and waits for the writer goroutine to pick it up, so the queue is empty
+// but the output is stuck
+func fillQueue(w *AsyncWriter, out *gatedWriter) {
+	w.Write([]byte("1\n"))
+	<-out.started
+}
+
+func TestAsyncWriter_Write_flushedInOrder(t *testing.T) {
+	out := &bytes.Buffer{}
+	w := NewAsyncWriter(out, 10, DropNewest)
+	defer w.Close()
+
+	for _, line := range []string{"a\n", "b\n", "c\n"} {
+		n, err := w.Write([]byte(line))
+		assert.Nil(t, err)
+		assert.Equal(t, 2, n)
+	}
+	w.Flush()
+
+	assert.Equal(t, "a\nb\nc\n", out.String())
+	assert.Zero(t, w.Dropped())
+}
+
+func TestAsyncWriter_Write_copiesLine(t *testing.T) {
+	out := &bytes.Buffer{}
+	w := NewAsyncWriter(out, 10, DropNewest)
+	defer w.Close()
+	line := []byte("a\n")
+
+	w.Write(line)
+	line[0] = 'z'
+	w.Flush()
+
+	assert.Equal(t, "a\n", out.String())
+}
+
+func TestAsyncWriter_Write_dropNewest(t *testing.T) {
+	out := newGatedWriter()
+	w := NewAsyncWriter(out, 1, DropNewest)
+	fillQueue(w, out)
+
+	w.Write([]byte("2\n"))
+	w.Write([]byte("3\n"))
+	close(out.release)
+	w.Close()
+
+	assert.Equal(t, "1\n2\n", out.String())
+	assert.Equal(t, uint64(1), w.Dropped())
+}
+
+func TestAsyncWriter_Write_dropOldest(t *testing.T) {
+	out := newGatedWriter()
+	w := NewAsyncWriter(out, 1, DropOldest)
+	fillQueue(w, out)
+
+	w.Write([]byte("2\n"))
+	w.Write([]byte("3\n"))
+	close(out.release)
+	w.Close()
+
+	assert.Equal(t, "1\n3\n", out.String())
+	assert.Equal(t, uint64(1), w.Dropped())
+}
+
+func TestAsyncWriter_Write_block(t *testing.T) {
+	out := newGatedWriter()
+	w := NewAsyncWriter(out, 1, Block)
+	fillQueue(w, out)
+	w.Write([]byte("2\n"))
+
+	written := make(chan struct{})
+	go func() {
+		w.Write([]byte("3\n"))
+		close(written)
+	}()
+	close(out.release)
+	<-written
+	w.Close()
+
+	assert.Equal(t, "1\n2\n3\n", out.String())
+	assert.Zero(t, w.Dropped())
+}
+
+func TestAsyncWriter_Close_writesThroughAfterwards(t *testing.T) {
+	out := &bytes.Buffer{}
+	w := NewAsyncWriter(out, 10, DropNewest)
+	w.Write([]byte("queued\n"))
+
+	assert.Nil(t, w.Close())
+	assert.Nil(t, w.Close())
+	w.Write([]byte("direct\n"))
+
+	assert.Equal(t, "queued\ndirect\n", out.String())
+}
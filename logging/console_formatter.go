@@ -0,0 +1,171 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	colorReset  = "\x1b[0m"
+	colorRed    = "\x1b[31m"
+	colorGreen  = "\x1b[32m"
+	colorYellow = "\x1b[33m"
+	colorCyan   = "\x1b[36m"
+	colorGray   = "\x1b[90m"
+
+	defaultMessageWidth = 44
+)
+
+// ConsoleFormatter writes entries as aligned, colored lines for reading logs locally, in the
+// same order as OrderedJSONFormatter: message, severity, time, function, error and then the
+// other fields sorted by key
+//
+//	Server listening                             INFO    10:30:00.123 function=...gin.Run port=8080
+type ConsoleFormatter struct {
+	// TimestampFormat defaults to a time of day with milliseconds
+	TimestampFormat string
+	// MessageWidth pads messages so the columns after them line up, defaults to 44
+	MessageWidth int
+	// DisableColors writes plain text, e.g. when output isn't a terminal
+	DisableColors bool
+
+	// Redactor and ErrorStacks work as they do on OrderedJSONFormatter
+	Redactor    *Redactor
+	ErrorStacks bool
+}
+
+// Format implements the logrus.Formatter interface
+func (f *ConsoleFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	var buf bytes.Buffer
+	data := f.Redactor.RedactFields(entry.Data)
+
+	// 1. Message, padded so the severity column lines up
+	message := f.Redactor.RedactString(entry.Message)
+	buf.WriteString(message)
+	width := f.MessageWidth
+	if width <= 0 {
+		width = defaultMessageWidth
+	}
+	if pad := width - utf8.RuneCountInString(message); pad > 0 {
+		buf.WriteString(strings.Repeat(" ", pad))
+	}
+
+	// 2. Severity
+	buf.WriteByte(' ')
+	severity := fmt.Sprintf("%-7s", strings.ToUpper(entry.Level.String()))
+	f.writeColored(&buf, levelColor(entry.Level), severity)
+
+	// 3. Time
+	timestampFormat := f.TimestampFormat
+	if timestampFormat == "" {
+		timestampFormat = "15:04:05.000"
+	}
+	buf.WriteByte(' ')
+	buf.WriteString(entry.Time.Format(timestampFormat))
+
+	// 4. Function
+	if entry.HasCaller() && entry.Caller.Function != "" {
+		buf.WriteByte(' ')
+		f.writeColored(&buf, colorGray, "function="+entry.Caller.Function)
+	}
+
+	// 5. Error, with its stack on the following lines
+	var stack []string
+	if value, hasError := data[logrus.ErrorKey]; hasError {
+		buf.WriteByte(' ')
+		if err, ok := value.(error); ok && err != nil {
+			message := NewErrorDetail(err, f.Redactor).Message
+			f.writeColored(&buf, colorRed, "error="+formatConsoleValue(message))
+			if f.ErrorStacks && wantsStack(entry.Level) {
+				stack = callerStack()
+			}
+		} else {
+			value = f.Redactor.RedactValue(logrus.ErrorKey, value)
+			f.writeColored(&buf, colorRed, "error="+formatConsoleValue(value))
+		}
+	}
+
+	// 6. Everything else, sorted by key
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		if k != logrus.ErrorKey && k != HTTPRequestKey {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		buf.WriteByte(' ')
+		f.writeColored(&buf, colorCyan, k)
+		buf.WriteByte('=')
+		buf.WriteString(formatConsoleValue(data[k]))
+	}
+	buf.WriteByte('\n')
+
+	for _, frame := range stack {
+		buf.WriteString("    ")
+		f.writeColored(&buf, colorGray, frame)
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (f *ConsoleFormatter) writeColored(buf *bytes.Buffer, color, text string) {
+	if f.DisableColors {
+		buf.WriteString(text)
+		return
+	}
+	buf.WriteString(color)
+	buf.WriteString(text)
+	buf.WriteString(colorReset)
+}
+
+func levelColor(level logrus.Level) string {
+	switch {
+	case level <= logrus.ErrorLevel:
+		return colorRed
+	case level == logrus.WarnLevel:
+		return colorYellow
+	case level == logrus.InfoLevel:
+		return colorGreen
+	default:
+		return colorGray
+	}
+}
+
+// formatConsoleValue writes strings bare unless they need quoting, and anything structured as JSON
+func formatConsoleValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		if v == "" || strings.ContainsAny(v, " \t\n\"=") {
+			return strconv.Quote(v)
+		}
+		return v
+	case error:
+		return strconv.Quote(v.Error())
+	case fmt.Stringer:
+		return formatConsoleValue(fmt.Sprint(v))
+	}
+	valueBytes, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(valueBytes)
+}
+
+// isTerminal reports whether f is a character device, i.e. someone is watching the output
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
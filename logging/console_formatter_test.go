@@ -0,0 +1,75 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsoleFormatter_Format_plain(t *testing.T) {
+	f := &ConsoleFormatter{DisableColors: true, MessageWidth: 10}
+	entry := newTestEntry(logrus.WarnLevel, logrus.Fields{
+		"port":          8080,
+		"path":          "/v0/ping",
+		"note":          "two words",
+		logrus.ErrorKey: errors.New("boom"),
+		"tags":          []string{"a", "b"},
+	})
+
+	result, err := f.Format(entry)
+
+	assert.Nil(t, err)
+	assert.Equal(
+		t,
+		`hello      WARNING 10:30:00.000 function=example.com/app.Run error=boom note="two words" path=/v0/ping port=8080 tags=["a","b"]`+"\n",
+		string(result),
+	)
+}
+
+func TestConsoleFormatter_Format_colored(t *testing.T) {
+	f := &ConsoleFormatter{MessageWidth: 1}
+	entry := newTestEntry(logrus.ErrorLevel, logrus.Fields{"port": 8080})
+
+	result, err := f.Format(entry)
+
+	assert.Nil(t, err)
+	assert.Equal(
+		t,
+		"hello "+colorRed+"ERROR  "+colorReset+" 10:30:00.000 "+colorGray+"function=example.com/app.Run"+colorReset+
+			" "+colorCyan+"port"+colorReset+"=8080\n",
+		string(result),
+	)
+}
+
+func TestConsoleFormatter_Format_redacted(t *testing.T) {
+	f := &ConsoleFormatter{DisableColors: true, MessageWidth: 1, Redactor: NewDefaultRedactor()}
+	entry := newTestEntry(logrus.InfoLevel, logrus.Fields{"password": "hunter2"})
+
+	result, err := f.Format(entry)
+
+	assert.Nil(t, err)
+	assert.Contains(t, string(result), "password=[REDACTED]")
+	assert.NotContains(t, string(result), "hunter2")
+}
+
+func TestConsoleFormatter_Format_errorStack(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := logrus.New()
+	logger.SetOutput(buf)
+	logger.SetFormatter(&ConsoleFormatter{DisableColors: true, ErrorStacks: true})
+
+	logger.WithError(errors.New("boom")).Error("failed")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Greater(t, len(lines), 1)
+	assert.Contains(t, lines[0], "error=boom")
+	assert.True(
+		t,
+		strings.HasPrefix(lines[1], "    github.com/Admiral-Piett/go-tools/logging.TestConsoleFormatter_Format_errorStack "),
+		lines[1],
+	)
+}
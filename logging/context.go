@@ -0,0 +1,68 @@
+package logging
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type fieldsKey struct{}
+
+// WithFields returns a context carrying extra log fields.  Any entry logged with that context
+// (`log.WithContext(ctx)`) gets them added by ContextHook, e.g. the request id.
+func WithFields(ctx context.Context, fields log.Fields) context.Context {
+	existing := FieldsFromContext(ctx)
+	merged := make(log.Fields, len(existing)+len(fields))
+	for k, v := range existing {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// WithField is WithFields for a single field
+func WithField(ctx context.Context, key string, value interface{}) context.Context {
+	return WithFields(ctx, log.Fields{key: value})
+}
+
+// FieldsFromContext returns the fields stored by WithFields.  Don't modify the result, it's
+// shared with every context derived from ctx.
+func FieldsFromContext(ctx context.Context) log.Fields {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsKey{}).(log.Fields)
+	return fields
+}
+
+// FromContext returns an entry carrying the request's fields (request id, route, trace id, user
+// id - whatever middleware has added), so every line logged for a request can be correlated:
+//
+//	logging.FromContext(c.Request.Context()).WithError(err).Error("Create Thing Failure")
+//
+// The fields are set on the entry directly, so this works whether or not ContextHook is installed.
+func FromContext(ctx context.Context) *log.Entry {
+	if ctx == nil {
+		return log.NewEntry(log.StandardLogger())
+	}
+	return log.WithContext(ctx).WithFields(FieldsFromContext(ctx))
+}
+
+// ContextHook copies fields stored with WithFields onto entries logged with that context.
+// Fields set directly on the entry win.  InitLogging installs it.
+type ContextHook struct{}
+
+func (h *ContextHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (h *ContextHook) Fire(entry *log.Entry) error {
+	for k, v := range FieldsFromContext(entry.Context) {
+		if _, ok := entry.Data[k]; !ok {
+			entry.Data[k] = v
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package logging
+
+import (
+	"context"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithFields_mergesWithoutModifyingParent(t *testing.T) {
+	parent := WithField(context.Background(), "request_id", "abc")
+
+	child := WithFields(parent, log.Fields{"user_id": "7", "request_id": "def"})
+
+	assert.Equal(t, log.Fields{"request_id": "abc"}, FieldsFromContext(parent))
+	assert.Equal(t, log.Fields{"request_id": "def", "user_id": "7"}, FieldsFromContext(child))
+}
+
+func TestFieldsFromContext_empty(t *testing.T) {
+	assert.Nil(t, FieldsFromContext(context.Background()))
+	assert.Nil(t, FieldsFromContext(nil))
+}
+
+func TestContextHook_Fire(t *testing.T) {
+	ctx := WithFields(context.Background(), log.Fields{"request_id": "abc", "route": "/users"})
+	entry := log.NewEntry(log.New()).WithContext(ctx).WithField("route", "explicit")
+
+	err := (&ContextHook{}).Fire(entry)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "abc", entry.Data["request_id"])
+	assert.Equal(t, "explicit", entry.Data["route"])
+}
+
+func TestContextHook_Fire_noContext(t *testing.T) {
+	entry := log.NewEntry(log.New())
+
+	err := (&ContextHook{}).Fire(entry)
+
+	assert.Nil(t, err)
+	assert.Empty(t, entry.Data)
+}
+
+func TestFromContext(t *testing.T) {
+	ctx := WithFields(context.Background(), log.Fields{"request_id": "abc", "user_id": "7"})
+
+	entry := FromContext(ctx)
+
+	assert.Equal(t, ctx, entry.Context)
+	assert.Equal(t, log.Fields{"request_id": "abc", "user_id": "7"}, entry.Data)
+}
+
+func TestFromContext_nil(t *testing.T) {
+	entry := FromContext(nil)
+
+	assert.NotNil(t, entry)
+	assert.Empty(t, entry.Data)
+}
@@ -0,0 +1,117 @@
+package logging
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// maxErrorChain caps how far a wrapped error chain is followed, in case of cycles
+	maxErrorChain = 32
+
+	logrusPackage  = "github.com/sirupsen/logrus."
+	slogPackage    = "log/slog."
+	loggingPackage = "github.com/Admiral-Piett/go-tools/logging."
+)
+
+// ErrorDetail is how the `error` field is written: the message and type of the logged error,
+// the errors it wraps (`errors.Unwrap`, or every branch of an `errors.Join`) and, for error
+// level entries and above, where it was logged from
+//
+//	"error":{"message":"load user: sql: no rows","type":"*fmt.wrapError","chain":[{"message":"sql: no rows","type":"*errors.errorString"}]}
+type ErrorDetail struct {
+	Message string      `json:"message"`
+	Type    string      `json:"type"`
+	Chain   []ErrorLink `json:"chain,omitempty"`
+	Stack   []string    `json:"stack,omitempty"`
+}
+
+// ErrorLink is one wrapped error in an ErrorDetail's chain
+type ErrorLink struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+}
+
+// NewErrorDetail describes err, redacting its messages with redactor (nil is fine)
+func NewErrorDetail(err error, redactor *Redactor) ErrorDetail {
+	detail := ErrorDetail{
+		Message: redactor.RedactString(err.Error()),
+		Type:    fmt.Sprintf("%T", err),
+	}
+	for _, wrapped := range unwrapAll(err) {
+		detail.Chain = append(detail.Chain, ErrorLink{
+			Message: redactor.RedactString(wrapped.Error()),
+			Type:    fmt.Sprintf("%T", wrapped),
+		})
+	}
+	return detail
+}
+
+// unwrapAll flattens everything err wraps, depth first
+func unwrapAll(err error) []error {
+	var chain []error
+	var walk func(error)
+	walk = func(err error) {
+		var children []error
+		switch e := err.(type) {
+		case interface{ Unwrap() error }:
+			if child := e.Unwrap(); child != nil {
+				children = []error{child}
+			}
+		case interface{ Unwrap() []error }:
+			children = e.Unwrap()
+		}
+		for _, child := range children {
+			if child == nil || len(chain) >= maxErrorChain {
+				continue
+			}
+			chain = append(chain, child)
+			walk(child)
+		}
+	}
+	walk(err)
+	return chain
+}
+
+// callerStack returns the stack above the logging call, as "function file:line" lines.  That's
+// everything past the last logrus or slog frame, or past this package's frames when Format is
+// called directly.
+func callerStack() []string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var all []runtime.Frame
+	start := -1
+	for {
+		frame, more := frames.Next()
+		if strings.HasPrefix(frame.Function, logrusPackage) ||
+			strings.HasPrefix(frame.Function, slogPackage) {
+			start = len(all) + 1
+		}
+		all = append(all, frame)
+		if !more {
+			break
+		}
+	}
+	if start < 0 {
+		start = 0
+		for start < len(all) && strings.HasPrefix(all[start].Function, loggingPackage) {
+			start++
+		}
+	}
+
+	var stack []string
+	for _, frame := range all[start:] {
+		stack = append(stack, fmt.Sprintf("%s %s:%d", frame.Function, frame.File, frame.Line))
+	}
+	return stack
+}
+
+// wantsStack reports whether an entry at level gets a stack trace
+func wantsStack(level logrus.Level) bool {
+	return level <= logrus.ErrorLevel
+}
@@ -0,0 +1,107 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewErrorDetail_wrappedChain(t *testing.T) {
+	base := &fs.PathError{Op: "open", Path: "/tmp/x", Err: fs.ErrNotExist}
+	err := fmt.Errorf("load config: %w", base)
+
+	result := NewErrorDetail(err, nil)
+
+	assert.Equal(t, ErrorDetail{
+		Message: "load config: open /tmp/x: file does not exist",
+		Type:    "*fmt.wrapError",
+		Chain: []ErrorLink{
+			{Message: "open /tmp/x: file does not exist", Type: "*fs.PathError"},
+			{Message: "file does not exist", Type: "*errors.errorString"},
+		},
+	}, result)
+}
+
+func TestNewErrorDetail_joined(t *testing.T) {
+	err := errors.Join(errors.New("first"), errors.New("second"))
+
+	result := NewErrorDetail(err, nil)
+
+	assert.Equal(t, "*errors.joinError", result.Type)
+	assert.Equal(t, []ErrorLink{
+		{Message: "first", Type: "*errors.errorString"},
+		{Message: "second", Type: "*errors.errorString"},
+	}, result.Chain)
+}
+
+func TestNewErrorDetail_redacted(t *testing.T) {
+	err := fmt.Errorf("call failed: %w", errors.New("rejected Bearer abc"))
+
+	result := NewErrorDetail(err, NewDefaultRedactor())
+
+	assert.Equal(t, "call failed: rejected Bearer "+Redacted, result.Message)
+	assert.Equal(t, "rejected Bearer "+Redacted, result.Chain[0].Message)
+}
+
+func TestOrderedJSONFormatter_Format_errorObject(t *testing.T) {
+	f := &OrderedJSONFormatter{}
+	entry := newTestEntry(logrus.WarnLevel, logrus.Fields{
+		logrus.ErrorKey: fmt.Errorf("load user: %w", errors.New("no rows")),
+		"user_id":       "7",
+	})
+
+	result, err := f.Format(entry)
+
+	assert.Nil(t, err)
+	assert.True(t, json.Valid(result))
+	assert.Equal(
+		t,
+		`{"message":"hello","severity":"warning","time":"2025-08-04T10:30:00Z","function":"example.com/app.Run",`+
+			`"error":{"message":"load user: no rows","type":"*fmt.wrapError","chain":[{"message":"no rows","type":"*errors.errorString"}]},`+
+			`"user_id":"7"}`+"\n",
+		string(result),
+	)
+}
+
+func TestOrderedJSONFormatter_Format_nonErrorValue(t *testing.T) {
+	f := &OrderedJSONFormatter{}
+	entry := newTestEntry(logrus.InfoLevel, logrus.Fields{logrus.ErrorKey: "just a string"})
+
+	result, err := f.Format(entry)
+
+	assert.Nil(t, err)
+	assert.Contains(t, string(result), `"error":"just a string"`)
+}
+
+func TestOrderedJSONFormatter_Format_errorStack(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := logrus.New()
+	logger.SetOutput(buf)
+	logger.SetFormatter(&OrderedJSONFormatter{ErrorStacks: true})
+
+	logger.WithError(errors.New("boom")).Warn("not an error level")
+	logger.WithError(errors.New("boom")).Error("failed")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+
+	warning := struct{ Error ErrorDetail }{}
+	assert.Nil(t, json.Unmarshal([]byte(lines[0]), &warning))
+	assert.Nil(t, warning.Error.Stack)
+
+	failure := struct{ Error ErrorDetail }{}
+	assert.Nil(t, json.Unmarshal([]byte(lines[1]), &failure))
+	assert.NotEmpty(t, failure.Error.Stack)
+	assert.True(
+		t,
+		strings.HasPrefix(failure.Error.Stack[0], "github.com/Admiral-Piett/go-tools/logging.TestOrderedJSONFormatter_Format_errorStack "),
+		failure.Error.Stack[0],
+	)
+}
@@ -15,18 +15,34 @@ import (
 type OrderedJSONFormatter struct {
 	// TimestampFormat sets the format used for marshaling timestamps.
 	TimestampFormat string
+
+	// GCP switches to Google Cloud Logging's special fields: uppercase severities,
+	// `logging.googleapis.com/trace`/`spanId` in place of `trace_id`/`span_id`,
+	// `logging.googleapis.com/sourceLocation` in place of `function` and the access log's
+	// `httpRequest` object.
+	GCP bool
+	// ProjectId qualifies trace ids as `projects/PROJECT_ID/traces/TRACE_ID` in GCP mode
+	ProjectId string
+
+	// Redactor masks sensitive fields and message contents, nil writes everything as is
+	Redactor *Redactor
+
+	// ErrorStacks adds the logging call's stack trace to the error object of error, fatal and
+	// panic entries
+	ErrorStacks bool
 }
 
 // Format implements the logrus.Formatter interface
 func (f *OrderedJSONFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 	var buf bytes.Buffer
+	data := f.Redactor.RedactFields(entry.Data)
 
 	// Start JSON object
 	buf.WriteByte('{')
 
 	// 1. Message field (always first)
 	buf.WriteString(`"message":`)
-	messageBytes, err := json.Marshal(entry.Message)
+	messageBytes, err := json.Marshal(f.Redactor.RedactString(entry.Message))
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal message: %v", err)
 	}
@@ -34,7 +50,11 @@ func (f *OrderedJSONFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 
 	// 2. Severity field (always second)
 	buf.WriteString(`,"severity":`)
-	severityBytes, err := json.Marshal(strings.ToLower(entry.Level.String()))
+	severity := strings.ToLower(entry.Level.String())
+	if f.GCP {
+		severity = gcpSeverity(entry.Level)
+	}
+	severityBytes, err := json.Marshal(severity)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal severity: %v", err)
 	}
@@ -53,7 +73,14 @@ func (f *OrderedJSONFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 	buf.Write(timeBytes)
 
 	// 4. Function field (always fourth) - if caller is reported
-	if entry.HasCaller() {
+	if entry.HasCaller() && f.GCP {
+		buf.WriteString(`,"` + gcpSourceLocationKey + `":`)
+		locationBytes, err := json.Marshal(newGcpSourceLocation(entry.Caller))
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal source location: %v", err)
+		}
+		buf.Write(locationBytes)
+	} else if entry.HasCaller() {
 		buf.WriteString(`,"function":`)
 		functionName := f.formatCaller(entry.Caller)
 		functionBytes, err := json.Marshal(functionName)
@@ -63,20 +90,21 @@ func (f *OrderedJSONFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 		buf.Write(functionBytes)
 	}
 
-	// 5. Error field (if present) - always after core fields
-	if entry.Data != nil {
-		if err, hasError := entry.Data[logrus.ErrorKey]; hasError {
+	// 5. Error field (if present) - always after core fields.  Errors are written as an
+	// ErrorDetail object, anything else logged under "error" as is.
+	if data != nil {
+		if value, hasError := data[logrus.ErrorKey]; hasError {
 			buf.WriteString(`,"error":`)
-			err, ok := err.(error)
-			if ok {
-				errorBytes, err := json.Marshal(err.Error())
-				if err != nil {
-					return nil, fmt.Errorf("failed to marshal error.Error(): %v", err)
+			if err, ok := value.(error); ok && err != nil {
+				detail := NewErrorDetail(err, f.Redactor)
+				if f.ErrorStacks && wantsStack(entry.Level) {
+					detail.Stack = callerStack()
 				}
-				buf.Write(errorBytes)
+				value = detail
+			} else {
+				value = f.Redactor.RedactValue(logrus.ErrorKey, value)
 			}
-
-			errorBytes, err := json.Marshal(err)
+			errorBytes, err := json.Marshal(value)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal error: %v", err)
 			}
@@ -84,12 +112,19 @@ func (f *OrderedJSONFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 		}
 	}
 
+	// GCP trace correlation and request fields follow the error
+	if f.GCP && data != nil {
+		if err := f.writeGcpFields(&buf, data); err != nil {
+			return nil, err
+		}
+	}
+
 	// 6. All other fields (sorted alphabetically for consistency)
-	if entry.Data != nil {
-		// Get all field keys except the error key (already handled)
+	if data != nil {
+		// Get all field keys except the ones already handled
 		var keys []string
-		for k := range entry.Data {
-			if k != logrus.ErrorKey {
+		for k := range data {
+			if !f.isSpecialField(k) {
 				keys = append(keys, k)
 			}
 		}
@@ -113,7 +148,7 @@ func (f *OrderedJSONFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 			buf.Write(keyBytes)
 			buf.WriteByte(':')
 
-			valueBytes, err := json.Marshal(entry.Data[k])
+			valueBytes, err := json.Marshal(data[k])
 			if err != nil {
 				return nil, fmt.Errorf(
 					"failed to marshal value for key %s: %v",
@@ -150,3 +185,44 @@ func (f *OrderedJSONFormatter) formatCaller(caller *runtime.Frame) string {
 	// We want to keep the full path for better traceability
 	return funcName
 }
+
+// writeGcpFields writes the trace, span and httpRequest fields Cloud Logging recognises
+func (f *OrderedJSONFormatter) writeGcpFields(buf *bytes.Buffer, data logrus.Fields) error {
+	if traceId, ok := data[TraceIdField].(string); ok && traceId != "" {
+		buf.WriteString(`,"` + gcpTraceKey + `":`)
+		traceBytes, err := json.Marshal(gcpTrace(f.ProjectId, traceId))
+		if err != nil {
+			return fmt.Errorf("failed to marshal trace: %v", err)
+		}
+		buf.Write(traceBytes)
+	}
+	if spanId, ok := data[SpanIdField].(string); ok && spanId != "" {
+		buf.WriteString(`,"` + gcpSpanIdKey + `":`)
+		spanBytes, err := json.Marshal(spanId)
+		if err != nil {
+			return fmt.Errorf("failed to marshal span id: %v", err)
+		}
+		buf.Write(spanBytes)
+	}
+	if httpRequest, ok := data[HTTPRequestKey]; ok {
+		buf.WriteString(`,"` + HTTPRequestKey + `":`)
+		requestBytes, err := json.Marshal(httpRequest)
+		if err != nil {
+			return fmt.Errorf("failed to marshal http request: %v", err)
+		}
+		buf.Write(requestBytes)
+	}
+	return nil
+}
+
+// isSpecialField reports whether a field is written in its own slot rather than with the
+// sorted fields
+func (f *OrderedJSONFormatter) isSpecialField(key string) bool {
+	switch key {
+	case logrus.ErrorKey, HTTPRequestKey:
+		return true
+	case TraceIdField, SpanIdField:
+		return f.GCP
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"encoding/json"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestEntry(level logrus.Level, data logrus.Fields) *logrus.Entry {
+	entry := logrus.NewEntry(logrus.New())
+	entry.Level = level
+	entry.Message = "hello"
+	entry.Time = time.Date(2025, 8, 4, 10, 30, 0, 0, time.UTC)
+	entry.Data = data
+	entry.Caller = &runtime.Frame{Function: "example.com/app.Run", File: "/app/main.go", Line: 42}
+	entry.Logger.SetReportCaller(true)
+	return entry
+}
+
+func TestOrderedJSONFormatter_Format_default(t *testing.T) {
+	f := &OrderedJSONFormatter{}
+	entry := newTestEntry(logrus.WarnLevel, logrus.Fields{
+		"b":            2,
+		"a":            1,
+		TraceIdField:   "105445aa7843bc8bf206b12000100000",
+		HTTPRequestKey: &HTTPRequest{Status: 200},
+	})
+
+	result, err := f.Format(entry)
+
+	assert.Nil(t, err)
+	assert.Equal(
+		t,
+		`{"message":"hello","severity":"warning","time":"2025-08-04T10:30:00Z","function":"example.com/app.Run","a":1,"b":2,"trace_id":"105445aa7843bc8bf206b12000100000"}`+"\n",
+		string(result),
+	)
+}
+
+func TestOrderedJSONFormatter_Format_gcp(t *testing.T) {
+	f := &OrderedJSONFormatter{GCP: true, ProjectId: "my-project"}
+	entry := newTestEntry(logrus.WarnLevel, logrus.Fields{
+		"a":            1,
+		TraceIdField:   "105445aa7843bc8bf206b12000100000",
+		SpanIdField:    "0000000000000001",
+		HTTPRequestKey: &HTTPRequest{RequestMethod: "GET", Status: 404, Latency: FormatLatency(0.0015)},
+	})
+
+	result, err := f.Format(entry)
+
+	assert.Nil(t, err)
+	assert.Equal(
+		t,
+		`{"message":"hello","severity":"WARNING","time":"2025-08-04T10:30:00Z",`+
+			`"logging.googleapis.com/sourceLocation":{"file":"/app/main.go","line":"42","function":"example.com/app.Run"},`+
+			`"logging.googleapis.com/trace":"projects/my-project/traces/105445aa7843bc8bf206b12000100000",`+
+			`"logging.googleapis.com/spanId":"0000000000000001",`+
+			`"httpRequest":{"requestMethod":"GET","status":404,"latency":"0.0015s"},"a":1}`+"\n",
+		string(result),
+	)
+	assert.True(t, json.Valid(result))
+}
+
+func TestOrderedJSONFormatter_Format_gcpWithoutProject(t *testing.T) {
+	f := &OrderedJSONFormatter{GCP: true}
+	entry := newTestEntry(logrus.InfoLevel, logrus.Fields{TraceIdField: "abc"})
+
+	result, err := f.Format(entry)
+
+	assert.Nil(t, err)
+	assert.Contains(t, string(result), `"logging.googleapis.com/trace":"abc"`)
+}
+
+func TestGcpSeverity(t *testing.T) {
+	assert.Equal(t, "DEBUG", gcpSeverity(logrus.TraceLevel))
+	assert.Equal(t, "DEBUG", gcpSeverity(logrus.DebugLevel))
+	assert.Equal(t, "INFO", gcpSeverity(logrus.InfoLevel))
+	assert.Equal(t, "WARNING", gcpSeverity(logrus.WarnLevel))
+	assert.Equal(t, "ERROR", gcpSeverity(logrus.ErrorLevel))
+	assert.Equal(t, "CRITICAL", gcpSeverity(logrus.FatalLevel))
+	assert.Equal(t, "ALERT", gcpSeverity(logrus.PanicLevel))
+}
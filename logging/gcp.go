@@ -0,0 +1,84 @@
+package logging
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Special fields Cloud Logging lifts out of a JSON log line, see
+// https://cloud.google.com/logging/docs/structured-logging#special-payload-fields
+const (
+	gcpTraceKey          = "logging.googleapis.com/trace"
+	gcpSpanIdKey         = "logging.googleapis.com/spanId"
+	gcpSourceLocationKey = "logging.googleapis.com/sourceLocation"
+
+	// HTTPRequestKey holds an `*HTTPRequest` on access log entries.  Only the GCP formatter
+	// writes it, the plain JSON output already has the same details as flat fields.
+	HTTPRequestKey = "httpRequest"
+)
+
+// HTTPRequest is Cloud Logging's `httpRequest` object, which gets a request rendered as a
+// request line in the log viewer and grouped with its trace
+type HTTPRequest struct {
+	RequestMethod string `json:"requestMethod,omitempty"`
+	RequestUrl    string `json:"requestUrl,omitempty"`
+	RequestSize   string `json:"requestSize,omitempty"`
+	Status        int    `json:"status,omitempty"`
+	ResponseSize  string `json:"responseSize,omitempty"`
+	UserAgent     string `json:"userAgent,omitempty"`
+	RemoteIp      string `json:"remoteIp,omitempty"`
+	Referer       string `json:"referer,omitempty"`
+	Latency       string `json:"latency,omitempty"`
+	Protocol      string `json:"protocol,omitempty"`
+}
+
+// FormatLatency renders a latency in seconds the way Cloud Logging expects, e.g. "0.001234s"
+func FormatLatency(seconds float64) string {
+	return strconv.FormatFloat(seconds, 'f', -1, 64) + "s"
+}
+
+type gcpSourceLocation struct {
+	File     string `json:"file"`
+	Line     string `json:"line"`
+	Function string `json:"function"`
+}
+
+func newGcpSourceLocation(caller *runtime.Frame) gcpSourceLocation {
+	return gcpSourceLocation{
+		File:     caller.File,
+		Line:     strconv.Itoa(caller.Line),
+		Function: caller.Function,
+	}
+}
+
+// gcpSeverity maps logrus levels onto Cloud Logging's severities
+func gcpSeverity(level logrus.Level) string {
+	switch level {
+	case logrus.TraceLevel, logrus.DebugLevel:
+		return "DEBUG"
+	case logrus.InfoLevel:
+		return "INFO"
+	case logrus.WarnLevel:
+		return "WARNING"
+	case logrus.ErrorLevel:
+		return "ERROR"
+	case logrus.FatalLevel:
+		return "CRITICAL"
+	case logrus.PanicLevel:
+		return "ALERT"
+	default:
+		return "DEFAULT"
+	}
+}
+
+// gcpTrace builds the fully qualified trace name Cloud Logging links to Cloud Trace with.
+// Without a project the bare id is still useful for searching.
+func gcpTrace(projectId, traceId string) string {
+	if projectId == "" {
+		return traceId
+	}
+	return fmt.Sprintf("projects/%s/traces/%s", projectId, traceId)
+}
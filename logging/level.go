@@ -0,0 +1,120 @@
+package logging
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ParseLevel understands every logrus level name in any case, including `WARNING` (the
+// spelling `SQL_LOG_LEVEL` uses) and `TRACE`
+func ParseLevel(level string) (log.Level, error) {
+	parsed, err := log.ParseLevel(strings.TrimSpace(level))
+	if err != nil {
+		return log.InfoLevel, fmt.Errorf("unknown log level %q", level)
+	}
+	return parsed, nil
+}
+
+// LevelStatus describes a logger's current level and any temporary override
+type LevelStatus struct {
+	Level     string     `json:"level"`
+	BaseLevel string     `json:"base_level"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// LevelController changes a logger's level at runtime, e.g. turning on debug logging for a few
+// minutes while chasing an incident, then putting it back without a redeploy
+type LevelController struct {
+	mu        sync.Mutex
+	logger    *log.Logger
+	base      log.Level
+	timer     *time.Timer
+	expiresAt time.Time
+}
+
+// NewLevelController controls logger, treating its current level as the one to revert to
+func NewLevelController(logger *log.Logger) *LevelController {
+	return &LevelController{
+		logger: logger,
+		base:   logger.GetLevel(),
+	}
+}
+
+var defaultLevels = NewLevelController(log.StandardLogger())
+
+// Levels controls the standard logger's level, InitLogging sets its base level
+func Levels() *LevelController {
+	return defaultLevels
+}
+
+// SetBaseLevel sets the level the logger runs at and reverts to, cancelling any override
+func (lc *LevelController) SetBaseLevel(level log.Level) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	lc.stopTimer()
+	lc.base = level
+	lc.logger.SetLevel(level)
+}
+
+// SetLevel overrides the level for ttl, after which the base level is restored.  A newer
+// override replaces the previous one and its timer.
+func (lc *LevelController) SetLevel(level log.Level, ttl time.Duration) LevelStatus {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	lc.stopTimer()
+	lc.logger.SetLevel(level)
+	lc.expiresAt = time.Now().Add(ttl).UTC()
+
+	var timer *time.Timer
+	timer = time.AfterFunc(ttl, func() {
+		lc.mu.Lock()
+		defer lc.mu.Unlock()
+		// A later override may have replaced us between firing and taking the lock
+		if lc.timer != timer {
+			return
+		}
+		lc.timer = nil
+		lc.logger.SetLevel(lc.base)
+		log.WithField("level", lc.base.String()).Info("Log Level Override Expired")
+	})
+	lc.timer = timer
+	return lc.status()
+}
+
+// Reset drops any override, restoring the base level now
+func (lc *LevelController) Reset() LevelStatus {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	lc.stopTimer()
+	lc.logger.SetLevel(lc.base)
+	return lc.status()
+}
+
+func (lc *LevelController) Status() LevelStatus {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	return lc.status()
+}
+
+func (lc *LevelController) status() LevelStatus {
+	status := LevelStatus{
+		Level:     lc.logger.GetLevel().String(),
+		BaseLevel: lc.base.String(),
+	}
+	if lc.timer != nil {
+		expiresAt := lc.expiresAt
+		status.ExpiresAt = &expiresAt
+	}
+	return status
+}
+
+func (lc *LevelController) stopTimer() {
+	if lc.timer != nil {
+		lc.timer.Stop()
+		lc.timer = nil
+	}
+}
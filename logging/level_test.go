@@ -0,0 +1,86 @@
+package logging
+
+import (
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLevel(t *testing.T) {
+	for input, expected := range map[string]log.Level{
+		"TRACE":   log.TraceLevel,
+		"DEBUG":   log.DebugLevel,
+		"info":    log.InfoLevel,
+		"WARN":    log.WarnLevel,
+		"WARNING": log.WarnLevel,
+		" ERROR ": log.ErrorLevel,
+	} {
+		level, err := ParseLevel(input)
+		assert.Nil(t, err, input)
+		assert.Equal(t, expected, level, input)
+	}
+}
+
+func TestParseLevel_unknown_error(t *testing.T) {
+	level, err := ParseLevel("LOUD")
+
+	assert.Error(t, err)
+	assert.Equal(t, log.InfoLevel, level)
+}
+
+func TestLevelController_SetLevel_revertsAfterTTL(t *testing.T) {
+	logger := log.New()
+	logger.SetLevel(log.InfoLevel)
+	lc := NewLevelController(logger)
+
+	status := lc.SetLevel(log.DebugLevel, 20*time.Millisecond)
+
+	assert.Equal(t, "debug", status.Level)
+	assert.Equal(t, "info", status.BaseLevel)
+	assert.NotNil(t, status.ExpiresAt)
+	assert.Equal(t, log.DebugLevel, logger.GetLevel())
+
+	assert.Eventually(t, func() bool {
+		return lc.Status().Level == "info"
+	}, time.Second, 5*time.Millisecond)
+	assert.Nil(t, lc.Status().ExpiresAt)
+}
+
+func TestLevelController_SetLevel_replacesPreviousOverride(t *testing.T) {
+	logger := log.New()
+	lc := NewLevelController(logger)
+
+	lc.SetLevel(log.DebugLevel, 10*time.Millisecond)
+	lc.SetLevel(log.TraceLevel, time.Hour)
+	time.Sleep(30 * time.Millisecond)
+
+	assert.Equal(t, log.TraceLevel, logger.GetLevel())
+	lc.Reset()
+}
+
+func TestLevelController_Reset(t *testing.T) {
+	logger := log.New()
+	lc := NewLevelController(logger)
+	lc.SetLevel(log.DebugLevel, time.Hour)
+
+	status := lc.Reset()
+
+	assert.Equal(t, "info", status.Level)
+	assert.Nil(t, status.ExpiresAt)
+	assert.Equal(t, log.InfoLevel, logger.GetLevel())
+}
+
+func TestLevelController_SetBaseLevel_cancelsOverride(t *testing.T) {
+	logger := log.New()
+	lc := NewLevelController(logger)
+	lc.SetLevel(log.DebugLevel, time.Hour)
+
+	lc.SetBaseLevel(log.WarnLevel)
+
+	status := lc.Status()
+	assert.Equal(t, "warning", status.Level)
+	assert.Equal(t, "warning", status.BaseLevel)
+	assert.Nil(t, status.ExpiresAt)
+}
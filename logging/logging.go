@@ -1,33 +1,243 @@
 package logging
 
 import (
+	"io"
+	"log/slog"
 	"os"
+	"sync"
+	"time"
+
+	"github.com/Admiral-Piett/go-tools/settings"
 
 	log "github.com/sirupsen/logrus"
 )
 
-// InitLogging configures the global logger for the entire application
-func InitLogging() {
-	// Set log level from environment
-	logLevel := os.Getenv("LOG_LEVEL")
-	switch logLevel {
-	case "DEBUG":
-		log.SetLevel(log.DebugLevel)
-	case "INFO":
-		log.SetLevel(log.InfoLevel)
-	case "WARN":
-		log.SetLevel(log.WarnLevel)
-	case "ERROR":
-		log.SetLevel(log.ErrorLevel)
-	default:
-		log.SetLevel(log.InfoLevel)
-	}
-
-	// Set custom JSON formatter with guaranteed field ordering
-	log.SetFormatter(&OrderedJSONFormatter{
-		TimestampFormat: "2006-01-02T15:04:05.000Z07:00", // ISO 8601 with milliseconds
-	})
+// Option configures InitLogging
+type Option func(*config)
+
+type config struct {
+	level        string
+	format       string
+	environment  string
+	projectId    string
+	secrets      []string
+	reportCaller bool
+	output       io.Writer
+	sampling     *samplingConfig
+	async        *asyncConfig
+}
+
+type asyncConfig struct {
+	queueSize int
+	policy    DropPolicy
+}
+
+type samplingConfig struct {
+	first, thereafter int
+	interval          time.Duration
+}
+
+// The Sampler and AsyncWriter InitLogging installed, if any, for Close
+var (
+	sampler     *Sampler
+	asyncWriter *AsyncWriter
+	exitHandler sync.Once
+)
+
+// WithSettings takes the level, format, environment, GCP project and the secrets to redact
+// from the app's settings
+func WithSettings(cfg *settings.BaseSettings) Option {
+	return func(c *config) {
+		c.level = cfg.LogLevel
+		c.format = cfg.LogFormat
+		if cfg.Environment != "" {
+			c.environment = cfg.Environment
+		}
+		c.projectId = cfg.GoogleCloudProject
+		c.secrets = []string{cfg.EncryptionKey, cfg.JwtHmacKey, cfg.ApiKeyHmacKey, cfg.RequestSigningKey}
+	}
+}
+
+// WithLevel sets the base level, see ParseLevel for the accepted names
+func WithLevel(level string) Option {
+	return func(c *config) {
+		c.level = level
+	}
+}
+
+// WithFormat picks the formatter: `json`, `gcp` or `console`
+func WithFormat(format string) Option {
+	return func(c *config) {
+		c.format = format
+	}
+}
+
+// WithReportCaller turns the `function` field on or off, it's on by default
+func WithReportCaller(enabled bool) Option {
+	return func(c *config) {
+		c.reportCaller = enabled
+	}
+}
+
+// WithOutput writes logs to every given writer instead of stderr
+func WithOutput(writers ...io.Writer) Option {
+	return func(c *config) {
+		c.output = io.MultiWriter(writers...)
+	}
+}
+
+// WithSampling caps identical lines (same level and message) at the first `first` per interval,
+// then every `thereafter`th, with a summary of what was dropped.  An interval of 0 or less uses
+// DefaultSampleInterval.  See Sampler.
+func WithSampling(first, thereafter int, interval time.Duration) Option {
+	return func(c *config) {
+		c.sampling = &samplingConfig{first: first, thereafter: thereafter, interval: interval}
+	}
+}
+
+// WithAsyncOutput writes logs from a background goroutine through a queue of queueSize lines,
+// so logging never waits on stdout.  See AsyncWriter, and call Close on shutdown.
+func WithAsyncOutput(queueSize int, policy DropPolicy) Option {
+	return func(c *config) {
+		c.async = &asyncConfig{queueSize: queueSize, policy: policy}
+	}
+}
+
+// InitLogging configures the global logger for the entire application.  Without options it
+// reads LOG_LEVEL, LOG_FORMAT, ENV and GOOGLE_CLOUD_PROJECT from the environment, most apps
+// should pass their settings instead:
+//
+//	logging.InitLogging(logging.WithSettings(&GLOBAL_SETTINGS.BaseSettings))
+func InitLogging(opts ...Option) {
+	cfg := &config{
+		level:       os.Getenv("LOG_LEVEL"),
+		format:      os.Getenv("LOG_FORMAT"),
+		environment: os.Getenv("ENV"),
+		projectId:   os.Getenv("GOOGLE_CLOUD_PROJECT"),
+		secrets: []string{
+			os.Getenv("ENCRYPTION_KEY"),
+			os.Getenv("JWT_HMAC_KEY"),
+			os.Getenv("API_KEY_HMAC_KEY"),
+			os.Getenv("REQUEST_SIGNING_KEY"),
+		},
+		reportCaller: true,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	// Let anything from a previous InitLogging finish writing before replacing it
+	Close()
+
+	if cfg.output != nil {
+		log.SetOutput(cfg.output)
+	}
+	log.SetFormatter(newFormatter(cfg, log.StandardLogger().Out))
+	if cfg.sampling != nil {
+		sampler = NewSampler(
+			log.StandardLogger(),
+			cfg.sampling.first,
+			cfg.sampling.thereafter,
+			cfg.sampling.interval,
+		)
+	}
+
+	if cfg.async != nil {
+		asyncWriter = NewAsyncWriter(log.StandardLogger().Out, cfg.async.queueSize, cfg.async.policy)
+		log.SetOutput(asyncWriter)
+		// log.Fatal exits straight away, make it write out the queue first
+		exitHandler.Do(func() { log.RegisterExitHandler(Close) })
+	}
+
+	// Set the level through the controller so runtime overrides revert to it
+	level, levelErr := ParseLevel(cfg.level)
+	Levels().SetBaseLevel(level)
 
 	// Enable reporting of calling function with full module path
-	log.SetReportCaller(true)
+	log.SetReportCaller(cfg.reportCaller)
+
+	// Add request scoped fields to entries logged with a request's context
+	replaceContextHook(log.StandardLogger())
+
+	// Send slog, and the standard library's log package behind it, through the same pipeline
+	slog.SetDefault(slog.New(NewSlogHandler(log.StandardLogger())))
+
+	if cfg.level != "" && levelErr != nil {
+		log.WithError(levelErr).Warn("Unknown Log Level, Defaulting To Info")
+	}
+}
+
+// replaceContextHook installs a single ContextHook, so calling InitLogging again doesn't add
+// the fields twice.  Any other hooks the app added are kept.
+func replaceContextHook(logger *log.Logger) {
+	hooks := make(log.LevelHooks)
+	for level, levelHooks := range logger.Hooks {
+		for _, hook := range levelHooks {
+			if _, ok := hook.(*ContextHook); !ok {
+				hooks[level] = append(hooks[level], hook)
+			}
+		}
+	}
+	hooks.Add(&ContextHook{})
+	logger.ReplaceHooks(hooks)
+}
+
+// newFormatter picks the formatter for cfg.format: `json` for our ordered JSON, `gcp` for JSON
+// with Cloud Logging's special fields (e.g. on Cloud Run) or `console` for readable lines.
+// Unset, it's `console` when ENV is development or stdout is a terminal and `json` everywhere
+// else.  Colors are only used when out, where the lines actually go, is a terminal too.
+func newFormatter(cfg *config, out io.Writer) log.Formatter {
+	// Mask credentials and our own keys however they end up being logged
+	redactor := NewDefaultRedactor(cfg.secrets...)
+
+	format := cfg.format
+	if format == "" && (cfg.environment == "development" || isTerminal(os.Stdout)) {
+		format = "console"
+	}
+	if format == "console" {
+		file, isFile := out.(*os.File)
+		return &ConsoleFormatter{
+			DisableColors: !isFile || !isTerminal(file),
+			Redactor:      redactor,
+			ErrorStacks:   true,
+		}
+	}
+
+	// Custom JSON formatter with guaranteed field ordering
+	return &OrderedJSONFormatter{
+		TimestampFormat: "2006-01-02T15:04:05.000Z07:00", // ISO 8601 with milliseconds
+		GCP:             format == "gcp",
+		ProjectId:       cfg.projectId,
+		Redactor:        redactor,
+		ErrorStacks:     true,
+	}
+}
+
+// Flush waits for queued log lines to be written, if InitLogging was given WithAsyncOutput
+func Flush() {
+	if asyncWriter != nil {
+		asyncWriter.Flush()
+	}
+}
+
+// Close writes out sampling summaries and anything still queued, then stops the background
+// goroutines.  Call it last thing on shutdown, after the server has stopped taking requests:
+//
+//	srv.Shutdown(ctx)
+//	logging.Close()
+//
+// Logging keeps working afterwards, synchronously.
+func Close() {
+	if sampler != nil {
+		sampler.Close()
+		if log.StandardLogger().Formatter == sampler {
+			log.SetFormatter(sampler.next)
+		}
+		sampler = nil
+	}
+	if asyncWriter != nil {
+		asyncWriter.Close()
+		log.SetOutput(asyncWriter.out)
+		asyncWriter = nil
+	}
 }
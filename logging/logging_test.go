@@ -0,0 +1,185 @@
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Admiral-Piett/go-tools/settings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// restoreStandardLogger undoes InitLogging's changes to the global logger after a test
+func restoreStandardLogger(t *testing.T) {
+	logger := log.StandardLogger()
+	out, formatter, level, reportCaller := logger.Out, logger.Formatter, logger.GetLevel(), logger.ReportCaller
+	hooks := logger.ReplaceHooks(make(log.LevelHooks))
+	defaultSlog := slog.Default()
+	t.Cleanup(func() {
+		Close()
+		slog.SetDefault(defaultSlog)
+		logger.SetOutput(out)
+		logger.SetFormatter(formatter)
+		logger.SetReportCaller(reportCaller)
+		logger.ReplaceHooks(hooks)
+		Levels().SetBaseLevel(level)
+	})
+}
+
+// redirectStdout points stdout at a file, so the tests don't depend on whether they're run from
+// a terminal
+func redirectStdout(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = f
+	t.Cleanup(func() {
+		os.Stdout = stdout
+		f.Close()
+	})
+}
+
+func TestInitLogging_withSettings(t *testing.T) {
+	restoreStandardLogger(t)
+	buf := &bytes.Buffer{}
+
+	InitLogging(
+		WithSettings(&settings.BaseSettings{
+			LogLevel:      "WARNING",
+			LogFormat:     "gcp",
+			EncryptionKey: "an-encryption-key",
+		}),
+		WithOutput(buf),
+		WithReportCaller(false),
+	)
+	log.Info("dropped")
+	log.Warn("leaked an-encryption-key")
+
+	assert.Equal(t, log.WarnLevel, log.GetLevel())
+	assert.Equal(t, "warning", Levels().Status().BaseLevel)
+	assert.IsType(t, &OrderedJSONFormatter{}, log.StandardLogger().Formatter)
+	assert.Regexp(
+		t,
+		`^\{"message":"leaked \[REDACTED\]","severity":"WARNING","time":"[^"]+"\}\n$`,
+		buf.String(),
+	)
+}
+
+func TestInitLogging_consoleInDevelopment(t *testing.T) {
+	restoreStandardLogger(t)
+
+	InitLogging(
+		WithSettings(&settings.BaseSettings{Environment: "development", LogLevel: "TRACE"}),
+		WithOutput(&bytes.Buffer{}),
+	)
+
+	assert.Equal(t, log.TraceLevel, log.GetLevel())
+	assert.Equal(t, &ConsoleFormatter{
+		DisableColors: true,
+		Redactor:      log.StandardLogger().Formatter.(*ConsoleFormatter).Redactor,
+		ErrorStacks:   true,
+	}, log.StandardLogger().Formatter)
+}
+
+func TestInitLogging_production_json(t *testing.T) {
+	restoreStandardLogger(t)
+	t.Setenv("ENV", "development")
+	redirectStdout(t)
+
+	// The settings win over ENV
+	InitLogging(
+		WithSettings(&settings.BaseSettings{Environment: "production"}),
+		WithOutput(&bytes.Buffer{}),
+	)
+
+	assert.IsType(t, &OrderedJSONFormatter{}, log.StandardLogger().Formatter)
+}
+
+func TestInitLogging_stdoutNotTerminal_json(t *testing.T) {
+	restoreStandardLogger(t)
+	t.Setenv("ENV", "")
+	redirectStdout(t)
+
+	InitLogging(WithOutput(&bytes.Buffer{}))
+
+	assert.IsType(t, &OrderedJSONFormatter{}, log.StandardLogger().Formatter)
+}
+
+func TestInitLogging_calledTwice_oneContextHook(t *testing.T) {
+	restoreStandardLogger(t)
+	other := &testHook{}
+	log.AddHook(other)
+
+	InitLogging(WithFormat("json"), WithOutput(&bytes.Buffer{}))
+	InitLogging(WithFormat("json"), WithOutput(&bytes.Buffer{}))
+
+	for _, hooks := range log.StandardLogger().Hooks {
+		contextHooks := 0
+		for _, hook := range hooks {
+			if _, ok := hook.(*ContextHook); ok {
+				contextHooks++
+			}
+		}
+		assert.Equal(t, 1, contextHooks)
+		assert.Contains(t, hooks, log.Hook(other))
+	}
+}
+
+// testHook is a hook InitLogging doesn't know about
+type testHook struct{}
+
+func (h *testHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (h *testHook) Fire(entry *log.Entry) error {
+	return nil
+}
+
+func TestInitLogging_unknownLevel_defaultsToInfo(t *testing.T) {
+	restoreStandardLogger(t)
+	buf := &bytes.Buffer{}
+
+	InitLogging(WithLevel("LOUD"), WithFormat("json"), WithOutput(buf))
+
+	assert.Equal(t, log.InfoLevel, log.GetLevel())
+	assert.Contains(t, buf.String(), "Unknown Log Level")
+}
+
+func TestInitLogging_asyncOutput_closeFlushes(t *testing.T) {
+	restoreStandardLogger(t)
+	buf := &bytes.Buffer{}
+
+	InitLogging(WithFormat("json"), WithOutput(buf), WithAsyncOutput(100, Block))
+	assert.IsType(t, &AsyncWriter{}, log.StandardLogger().Out)
+	log.Info("queued")
+	Close()
+
+	assert.Contains(t, buf.String(), `"message":"queued"`)
+	_, async := log.StandardLogger().Out.(*AsyncWriter)
+	assert.False(t, async)
+}
+
+func TestInitLogging_sampling_closeRestoresFormatter(t *testing.T) {
+	restoreStandardLogger(t)
+	buf := &bytes.Buffer{}
+
+	InitLogging(WithFormat("json"), WithOutput(buf), WithSampling(1, 0, time.Hour))
+	assert.IsType(t, &Sampler{}, log.StandardLogger().Formatter)
+	log.Info("polling")
+	log.Info("polling")
+	Close()
+	log.Info("polling")
+	log.Info("polling")
+
+	assert.IsType(t, &OrderedJSONFormatter{}, log.StandardLogger().Formatter)
+	// The first line, the summary, then everything after Close
+	assert.Len(t, logLines(buf), 4)
+}
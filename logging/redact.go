@@ -0,0 +1,367 @@
+package logging
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Redacted replaces sensitive values in log output
+const Redacted = "[REDACTED]"
+
+// DefaultRedactKeys match field names (and nested map/struct keys) whose values are always
+// masked.  Keys are lower-cased with `-` and camelCase word breaks turned into `_` before
+// matching, so `X-Api-Key`, `xApiKey` and `x_api_key` are the same key.
+var DefaultRedactKeys = []string{
+	`^pass(word|wd)?$`,
+	`_password$`,
+	`secret(_?key)?$`,
+	`^(access_|refresh_|id_|bearer_|auth_)?token$`,
+	`^(proxy_)?authorization$`,
+	`(^|_)cookie$`,
+	`^(x_)?api_?key$`,
+	`(encryption|private|hmac|signing)_?key$`,
+}
+
+// ValueDetector masks sensitive parts of a string value, returning it unchanged if there are none
+type ValueDetector func(value string) string
+
+var (
+	jwtPattern    = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	bearerPattern = regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9._~+/=-]+`)
+	cardPattern   = regexp.MustCompile(`\b[2-6]\d(?:[ -]?\d){11,17}\b`)
+)
+
+// DetectJWT masks anything shaped like a JWT (`eyJ...`.`eyJ...`.`...`)
+func DetectJWT(value string) string {
+	return jwtPattern.ReplaceAllString(value, Redacted)
+}
+
+// DetectBearerToken masks the credentials in `Bearer ...` and `Basic ...` authorization values
+func DetectBearerToken(value string) string {
+	return bearerPattern.ReplaceAllString(value, "$1 "+Redacted)
+}
+
+// DetectCardNumber masks 13-19 digit card numbers, optionally split by spaces or dashes, that
+// pass the Luhn check
+func DetectCardNumber(value string) string {
+	return cardPattern.ReplaceAllStringFunc(value, func(match string) string {
+		if luhnValid(match) {
+			return Redacted
+		}
+		return match
+	})
+}
+
+// DetectLiteral masks exact occurrences of known secrets, e.g. the encryption key.  Secrets
+// shorter than 8 characters are ignored, they'd mask too much unrelated output.
+func DetectLiteral(secrets ...string) ValueDetector {
+	var known []string
+	for _, secret := range secrets {
+		if len(secret) >= 8 {
+			known = append(known, secret)
+		}
+	}
+	return func(value string) string {
+		for _, secret := range known {
+			value = strings.ReplaceAll(value, secret, Redacted)
+		}
+		return value
+	}
+}
+
+func luhnValid(number string) bool {
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// Redactor masks sensitive values before they're written.  Values under a matching key are
+// replaced outright, every other string, including ones nested in maps, slices and structs, is
+// run through the detectors.  A nil Redactor leaves everything as is.
+type Redactor struct {
+	keys      []*regexp.Regexp
+	detectors []ValueDetector
+}
+
+// NewRedactor compiles the key patterns, see DefaultRedactKeys
+func NewRedactor(keyPatterns []string, detectors ...ValueDetector) (*Redactor, error) {
+	r := &Redactor{detectors: detectors}
+	for _, pattern := range keyPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		r.keys = append(r.keys, re)
+	}
+	return r, nil
+}
+
+// NewDefaultRedactor masks the DefaultRedactKeys, JWTs, bearer tokens, card numbers and the
+// given secrets, e.g. the app's encryption and signing keys
+func NewDefaultRedactor(secrets ...string) *Redactor {
+	r, _ := NewRedactor(
+		DefaultRedactKeys,
+		DetectJWT,
+		DetectBearerToken,
+		DetectCardNumber,
+		DetectLiteral(secrets...),
+	)
+	return r
+}
+
+// RedactFields returns a copy of fields with sensitive values masked.  Errors are left alone.
+func (r *Redactor) RedactFields(fields log.Fields) log.Fields {
+	if r == nil || fields == nil {
+		return fields
+	}
+	redacted := make(log.Fields, len(fields))
+	for k, v := range fields {
+		if k == log.ErrorKey {
+			redacted[k] = v
+			continue
+		}
+		redacted[k] = r.RedactValue(k, v)
+	}
+	return redacted
+}
+
+// RedactValue masks a single value logged under key
+func (r *Redactor) RedactValue(key string, value interface{}) interface{} {
+	if r == nil || value == nil {
+		return value
+	}
+	if r.IsSensitiveKey(key) {
+		return Redacted
+	}
+
+	switch v := value.(type) {
+	case string:
+		return r.RedactString(v)
+	case error:
+		return value
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return value
+	}
+
+	// Values with nothing to redact are returned as is, to keep their own field order and save
+	// marshalling them twice.  The rest are walked in their JSON form, since that's what ends up
+	// in the log.
+	if !r.needsRedacting(reflect.ValueOf(value), 0) {
+		return value
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var tree interface{}
+	if err := decoder.Decode(&tree); err != nil {
+		return value
+	}
+	tree, changed := r.redactTree(tree)
+	if !changed {
+		return value
+	}
+	return tree
+}
+
+// RedactString runs a string through the detectors, e.g. for log messages
+func (r *Redactor) RedactString(value string) string {
+	if r == nil {
+		return value
+	}
+	for _, detect := range r.detectors {
+		value = detect(value)
+	}
+	return value
+}
+
+// IsSensitiveKey reports whether values under key are always masked
+func (r *Redactor) IsSensitiveKey(key string) bool {
+	if r == nil {
+		return false
+	}
+	normalized := normalizeKey(key)
+	for _, re := range r.keys {
+		if re.MatchString(normalized) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeKey lower cases key, turning `-` and camelCase word breaks into `_`, e.g.
+// `clientSecretKey` and `APIKey` become `client_secret_key` and `api_key`
+func normalizeKey(key string) string {
+	runes := []rune(key)
+	var b strings.Builder
+	b.Grow(len(key) + 4)
+	for i, c := range runes {
+		if c == '-' {
+			b.WriteByte('_')
+			continue
+		}
+		if unicode.IsUpper(c) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				b.WriteByte('_')
+			}
+			c = unicode.ToLower(c)
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// maxRedactDepth bounds needsRedacting on self referencing values, anything deeper is left to
+// the JSON walk
+const maxRedactDepth = 32
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// needsRedacting walks v the way encoding/json would, reporting whether anything in it would be
+// masked.  Types with their own marshalling can't be seen into, so they're assumed to need it.
+func (r *Redactor) needsRedacting(v reflect.Value, depth int) bool {
+	if !v.IsValid() {
+		return false
+	}
+	if depth > maxRedactDepth {
+		return true
+	}
+	t := v.Type()
+	if t == timeType {
+		return false
+	}
+	if t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) {
+		return true
+	}
+	if v.CanAddr() {
+		ptr := reflect.PointerTo(t)
+		if ptr.Implements(jsonMarshalerType) || ptr.Implements(textMarshalerType) {
+			return true
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return !v.IsNil() && r.needsRedacting(v.Elem(), depth+1)
+	case reflect.String:
+		s := v.String()
+		return r.RedactString(s) != s
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			// Non string keys are written in a form we can't cheaply check
+			return true
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			if r.IsSensitiveKey(iter.Key().String()) || r.needsRedacting(iter.Value(), depth+1) {
+				return true
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		// []byte is written as base64
+		if t.Elem().Kind() == reflect.Uint8 {
+			return false
+		}
+		for i := 0; i < v.Len(); i++ {
+			if r.needsRedacting(v.Index(i), depth+1) {
+				return true
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+			// Embedded structs' fields are promoted into this one
+			if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+				if r.needsRedacting(v.Field(i), depth+1) {
+					return true
+				}
+				continue
+			}
+			if !field.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			if r.IsSensitiveKey(name) || r.needsRedacting(v.Field(i), depth+1) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r *Redactor) redactTree(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		changed := false
+		for k, child := range v {
+			if r.IsSensitiveKey(k) {
+				v[k] = Redacted
+				changed = true
+				continue
+			}
+			if redacted, childChanged := r.redactTree(child); childChanged {
+				v[k] = redacted
+				changed = true
+			}
+		}
+		return v, changed
+	case []interface{}:
+		changed := false
+		for i, child := range v {
+			if redacted, childChanged := r.redactTree(child); childChanged {
+				v[i] = redacted
+				changed = true
+			}
+		}
+		return v, changed
+	case string:
+		redacted := r.RedactString(v)
+		return redacted, redacted != v
+	}
+	return value, false
+}
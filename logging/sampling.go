@@ -0,0 +1,144 @@
+package logging
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// SuppressedField holds the number of lines a sampling summary stands for
+const SuppressedField = "suppressed"
+
+// DefaultSampleInterval is used when a Sampler is given an interval of 0 or less
+const DefaultSampleInterval = time.Second
+
+// suppressedCount marks the sampler's own summary lines so they're never sampled themselves
+type suppressedCount int
+
+type sampleKey struct {
+	level   log.Level
+	message string
+}
+
+// Sampler stops one noisy call site from flooding the logs, e.g. a failing dependency logging
+// the same error on every request.  Within each interval it writes the first `first` lines with
+// the same level and message, then every `thereafter`th one, and drops the rest.  At the end of
+// the interval each dropped batch is collapsed into one summary line:
+//
+//	{"message":"Log Lines Suppressed","severity":"error",...,"sampled_message":"Upstream Failure","suppressed":48211}
+//
+// It wraps the logger's formatter, so hooks still see every entry.  Fatal and panic entries are
+// never sampled.
+type Sampler struct {
+	logger     *log.Logger
+	next       log.Formatter
+	first      int
+	thereafter int
+
+	mu      sync.Mutex
+	counts  map[sampleKey]int
+	dropped map[sampleKey]int
+	stopped bool
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// NewSampler wraps logger's current formatter and starts summarising every interval, or every
+// DefaultSampleInterval if it's 0 or less.  thereafter 0 drops everything past the first lines.
+// Call Close on shutdown.
+func NewSampler(logger *log.Logger, first, thereafter int, interval time.Duration) *Sampler {
+	if interval <= 0 {
+		interval = DefaultSampleInterval
+	}
+	s := newSampler(logger, first, thereafter)
+	logger.SetFormatter(s)
+	go s.run(interval)
+	return s
+}
+
+func newSampler(logger *log.Logger, first, thereafter int) *Sampler {
+	return &Sampler{
+		logger:     logger,
+		next:       logger.Formatter,
+		first:      first,
+		thereafter: thereafter,
+		counts:     map[sampleKey]int{},
+		dropped:    map[sampleKey]int{},
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// Format implements the logrus.Formatter interface, returning nothing for dropped entries
+func (s *Sampler) Format(entry *log.Entry) ([]byte, error) {
+	if _, summary := entry.Data[SuppressedField].(suppressedCount); summary ||
+		entry.Level <= log.FatalLevel {
+		return s.next.Format(entry)
+	}
+	if !s.sample(sampleKey{level: entry.Level, message: entry.Message}) {
+		return nil, nil
+	}
+	return s.next.Format(entry)
+}
+
+func (s *Sampler) sample(key sampleKey) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return true
+	}
+	s.counts[key]++
+	count := s.counts[key]
+	if count <= s.first || (s.thereafter > 0 && (count-s.first)%s.thereafter == 0) {
+		return true
+	}
+	s.dropped[key]++
+	return false
+}
+
+// Close stops the sampler, writing summaries for anything dropped in the current interval.
+// Entries logged afterwards are passed straight through.
+func (s *Sampler) Close() {
+	s.once.Do(func() {
+		close(s.stop)
+		<-s.done
+	})
+}
+
+func (s *Sampler) run(interval time.Duration) {
+	defer close(s.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.flush()
+		case <-s.stop:
+			s.mu.Lock()
+			s.stopped = true
+			s.mu.Unlock()
+			s.flush()
+			return
+		}
+	}
+}
+
+// flush starts a new interval and writes a summary per dropped batch from the last one.  The
+// summaries are logged after unlocking, logrus calls Format under its own lock.
+func (s *Sampler) flush() {
+	s.mu.Lock()
+	dropped := s.dropped
+	s.counts = map[sampleKey]int{}
+	s.dropped = map[sampleKey]int{}
+	s.mu.Unlock()
+
+	for key, count := range dropped {
+		s.logger.WithFields(log.Fields{
+			"sampled_message": key.message,
+			SuppressedField:   suppressedCount(count),
+		}).Log(key.level, "Log Lines Suppressed")
+	}
+}
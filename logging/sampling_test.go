@@ -0,0 +1,129 @@
+package logging
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// newSampledLogger returns a logger sampled by s without the background interval, tests call
+// flush themselves
+func newSampledLogger(buf *bytes.Buffer, first, thereafter int) (*log.Logger, *Sampler) {
+	logger := log.New()
+	logger.SetOutput(buf)
+	logger.SetFormatter(&OrderedJSONFormatter{TimestampFormat: "2006"})
+	s := newSampler(logger, first, thereafter)
+	logger.SetFormatter(s)
+	return logger, s
+}
+
+func logLines(buf *bytes.Buffer) []string {
+	trimmed := strings.TrimSpace(buf.String())
+	if trimmed == "" {
+		return nil
+	}
+	return strings.Split(trimmed, "\n")
+}
+
+func TestSampler_firstThenEveryNth(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, _ := newSampledLogger(buf, 2, 3)
+
+	for i := 0; i < 10; i++ {
+		logger.Error("Upstream Failure")
+	}
+
+	// 1st, 2nd, then the 5th and 8th
+	assert.Len(t, logLines(buf), 4)
+}
+
+func TestSampler_keyedByLevelAndMessage(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, _ := newSampledLogger(buf, 1, 0)
+
+	logger.Error("Upstream Failure")
+	logger.Error("Upstream Failure")
+	logger.Warn("Upstream Failure")
+	logger.Error("Other Failure")
+
+	assert.Len(t, logLines(buf), 3)
+}
+
+func TestSampler_flush_writesSummaryAndResets(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, s := newSampledLogger(buf, 1, 0)
+	for i := 0; i < 5; i++ {
+		logger.Error("Upstream Failure")
+	}
+
+	s.flush()
+	logger.Error("Upstream Failure")
+
+	lines := logLines(buf)
+	assert.Len(t, lines, 3)
+	assert.Contains(t, lines[1], `{"message":"Log Lines Suppressed","severity":"error",`)
+	assert.Contains(t, lines[1], `"sampled_message":"Upstream Failure","suppressed":4}`)
+	assert.Contains(t, lines[2], `"message":"Upstream Failure"`)
+}
+
+func TestSampler_flush_nothingDropped(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, s := newSampledLogger(buf, 5, 0)
+	logger.Error("Upstream Failure")
+
+	s.flush()
+
+	assert.Len(t, logLines(buf), 1)
+}
+
+func TestSampler_Close_flushes(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := log.New()
+	logger.SetOutput(buf)
+	logger.SetFormatter(&OrderedJSONFormatter{})
+	s := NewSampler(logger, 1, 0, time.Hour)
+
+	logger.Info("Polling")
+	logger.Info("Polling")
+	s.Close()
+	s.Close()
+
+	lines := logLines(buf)
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[1], `"suppressed":1`)
+}
+
+func TestSampler_afterClose_passesThrough(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := log.New()
+	logger.SetOutput(buf)
+	logger.SetFormatter(&OrderedJSONFormatter{})
+	s := NewSampler(logger, 1, 0, time.Hour)
+
+	logger.Info("Polling")
+	s.Close()
+	logger.Info("Polling")
+	logger.Info("Polling")
+
+	assert.Len(t, logLines(buf), 3)
+}
+
+func TestNewSampler_zeroInterval_usesDefault(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := log.New()
+	logger.SetOutput(buf)
+	logger.SetFormatter(&OrderedJSONFormatter{TimestampFormat: "2006"})
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		s := NewSampler(logger, 1, 0, interval)
+		logger.Error("Upstream Failure")
+		s.Close()
+		logger.SetFormatter(s.next)
+	}
+
+	assert.Len(t, logLines(buf), 2)
+}
@@ -0,0 +1,222 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// SlogHandler is a `log/slog` handler that writes through a logrus logger, using its level,
+// hooks, formatter and output, so slog and logrus lines from the same service are identical:
+//
+//	slog.New(logging.NewSlogHandler(log.StandardLogger())).Info("Server listening", "port", 8080)
+//	log.WithField("port", 8080).Info("Server listening")
+//
+// Groups become nested objects, and an error logged under "error" or "err" is written as the
+// `error` field.  InitLogging makes it slog's default handler.
+type SlogHandler struct {
+	logger *log.Logger
+	preset []slogAttrs
+	groups []string
+}
+
+// slogAttrs are attributes added with WithAttrs, under the groups open at the time
+type slogAttrs struct {
+	groups []string
+	attrs  []slog.Attr
+}
+
+// NewSlogHandler writes through logger, adding a hook to it that points the `function` field at
+// slog's caller rather than the handler
+func NewSlogHandler(logger *log.Logger) *SlogHandler {
+	addSlogCallerHook(logger)
+	return &SlogHandler{logger: logger}
+}
+
+// Enabled implements slog.Handler, following the logger's level
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.IsLevelEnabled(logrusLevel(level))
+}
+
+// Handle implements slog.Handler
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	fields := log.Fields{}
+	for _, preset := range h.preset {
+		addSlogAttrs(fields, preset.groups, preset.attrs)
+	}
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	addSlogAttrs(fields, h.groups, attrs)
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if r.PC != 0 {
+		ctx = context.WithValue(ctx, slogCallerKey{}, r.PC)
+	}
+
+	// Going through Log takes the logger's locks, the same as any other logrus call.  Log
+	// reports problems formatting or writing the entry itself.
+	entry := log.NewEntry(h.logger).WithContext(ctx).WithFields(fields).WithTime(r.Time)
+	entry.Log(logrusLevel(r.Level), r.Message)
+	return nil
+}
+
+type slogCallerKey struct{}
+
+// slogCallerHook swaps the caller logrus found, which is always the handler, for slog's caller
+type slogCallerHook struct{}
+
+func (h *slogCallerHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (h *slogCallerHook) Fire(entry *log.Entry) error {
+	if entry.Caller == nil || entry.Context == nil {
+		return nil
+	}
+	if pc, ok := entry.Context.Value(slogCallerKey{}).(uintptr); ok {
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		entry.Caller = &frame
+	}
+	return nil
+}
+
+// addSlogCallerHook adds slogCallerHook to logger once
+func addSlogCallerHook(logger *log.Logger) {
+	for _, hook := range logger.Hooks[log.InfoLevel] {
+		if _, ok := hook.(*slogCallerHook); ok {
+			return
+		}
+	}
+	logger.AddHook(&slogCallerHook{})
+}
+
+// WithAttrs implements slog.Handler
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	clone := *h
+	clone.preset = append(h.preset[:len(h.preset):len(h.preset)], slogAttrs{groups: h.groups, attrs: attrs})
+	return &clone
+}
+
+// WithGroup implements slog.Handler
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.groups = append(h.groups[:len(h.groups):len(h.groups)], name)
+	return &clone
+}
+
+// logrusLevel maps slog levels, including the ones in between, onto logrus'
+func logrusLevel(level slog.Level) log.Level {
+	switch {
+	case level < slog.LevelDebug:
+		return log.TraceLevel
+	case level < slog.LevelInfo:
+		return log.DebugLevel
+	case level < slog.LevelWarn:
+		return log.InfoLevel
+	case level < slog.LevelError:
+		return log.WarnLevel
+	default:
+		return log.ErrorLevel
+	}
+}
+
+// addSlogAttrs adds attrs to fields under groups, leaving out groups that end up empty
+func addSlogAttrs(fields log.Fields, groups []string, attrs []slog.Attr) {
+	target := map[string]interface{}(fields)
+	if len(groups) > 0 {
+		target = map[string]interface{}{}
+	}
+	for _, a := range attrs {
+		addSlogAttr(target, a, len(groups) == 0)
+	}
+	if len(groups) == 0 || len(target) == 0 {
+		return
+	}
+
+	parent := map[string]interface{}(fields)
+	for _, group := range groups[:len(groups)-1] {
+		child, ok := parent[group].(map[string]interface{})
+		if !ok {
+			child = map[string]interface{}{}
+			parent[group] = child
+		}
+		parent = child
+	}
+	last := groups[len(groups)-1]
+	if existing, ok := parent[last].(map[string]interface{}); ok {
+		for k, v := range target {
+			existing[k] = v
+		}
+		return
+	}
+	parent[last] = target
+}
+
+func addSlogAttr(target map[string]interface{}, a slog.Attr, topLevel bool) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		groupAttrs := a.Value.Group()
+		if len(groupAttrs) == 0 {
+			return
+		}
+		// Unnamed groups are inlined
+		if a.Key == "" {
+			for _, ga := range groupAttrs {
+				addSlogAttr(target, ga, topLevel)
+			}
+			return
+		}
+		child := map[string]interface{}{}
+		for _, ga := range groupAttrs {
+			addSlogAttr(child, ga, false)
+		}
+		if len(child) > 0 {
+			target[a.Key] = child
+		}
+		return
+	}
+
+	if err, ok := a.Value.Any().(error); ok && topLevel && (a.Key == "error" || a.Key == "err") {
+		target[log.ErrorKey] = err
+		return
+	}
+	target[a.Key] = slogValue(a.Value)
+}
+
+func slogValue(v slog.Value) interface{} {
+	switch v.Kind() {
+	case slog.KindString:
+		return v.String()
+	case slog.KindInt64:
+		return v.Int64()
+	case slog.KindUint64:
+		return v.Uint64()
+	case slog.KindFloat64:
+		return v.Float64()
+	case slog.KindBool:
+		return v.Bool()
+	case slog.KindDuration:
+		return v.Duration()
+	case slog.KindTime:
+		return v.Time()
+	default:
+		return v.Any()
+	}
+}
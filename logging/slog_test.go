@@ -0,0 +1,142 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// newSlogTestLogger returns a logrus logger writing ordered JSON to buf, and a slog logger
+// writing through it
+func newSlogTestLogger(buf *bytes.Buffer) (*log.Logger, *slog.Logger) {
+	logger := log.New()
+	logger.SetOutput(buf)
+	// Year only, so lines logged a moment apart still match
+	logger.SetFormatter(&OrderedJSONFormatter{TimestampFormat: "2006"})
+	logger.SetReportCaller(true)
+	logger.SetLevel(log.DebugLevel)
+	logger.AddHook(&ContextHook{})
+	return logger, slog.New(NewSlogHandler(logger))
+}
+
+func TestSlogHandler_matchesLogrusOutput(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, slogger := newSlogTestLogger(buf)
+	err := fmt.Errorf("load user: %w", errors.New("no rows"))
+	ctx := WithField(context.Background(), "request_id", "req-1")
+
+	logger.WithContext(ctx).WithError(err).WithFields(log.Fields{
+		"user_id":  "7",
+		"attempts": 3,
+		"took":     time.Second,
+		"request":  map[string]interface{}{"method": "GET", "path": "/v0/ping"},
+	}).Warn("Lookup Failure")
+	slogger.WarnContext(ctx, "Lookup Failure",
+		"user_id", "7",
+		"err", err,
+		slog.Int("attempts", 3),
+		slog.Duration("took", time.Second),
+		slog.Group("request", "path", "/v0/ping", "method", "GET"),
+	)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Equal(t, lines[0], lines[1])
+	assert.Contains(t, lines[1], `"function":"github.com/Admiral-Piett/go-tools/logging.TestSlogHandler_matchesLogrusOutput"`)
+	assert.Contains(t, lines[1], `"request_id":"req-1"`)
+}
+
+func TestSlogHandler_WithAttrsAndGroups(t *testing.T) {
+	buf := &bytes.Buffer{}
+	_, slogger := newSlogTestLogger(buf)
+
+	slogger.With("service", "api").
+		WithGroup("http").With("method", "GET").
+		WithGroup("response").
+		Info("Handled", "status", 200, slog.Group("empty"))
+
+	assert.Contains(
+		t,
+		buf.String(),
+		`"http":{"method":"GET","response":{"status":200}},"service":"api"}`,
+	)
+}
+
+func TestSlogHandler_emptyGroupOmitted(t *testing.T) {
+	buf := &bytes.Buffer{}
+	_, slogger := newSlogTestLogger(buf)
+
+	slogger.WithGroup("request").Info("No Attributes")
+
+	assert.NotContains(t, buf.String(), "request")
+}
+
+func TestSlogHandler_Enabled_followsLogrusLevel(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, slogger := newSlogTestLogger(buf)
+	logger.SetLevel(log.WarnLevel)
+
+	slogger.Info("dropped")
+	slogger.Warn("kept")
+
+	assert.NotContains(t, buf.String(), "dropped")
+	assert.Contains(t, buf.String(), `"message":"kept","severity":"warning"`)
+}
+
+func TestLogrusLevel(t *testing.T) {
+	assert.Equal(t, log.TraceLevel, logrusLevel(slog.LevelDebug-4))
+	assert.Equal(t, log.DebugLevel, logrusLevel(slog.LevelDebug))
+	assert.Equal(t, log.InfoLevel, logrusLevel(slog.LevelInfo))
+	assert.Equal(t, log.InfoLevel, logrusLevel(slog.LevelInfo+2))
+	assert.Equal(t, log.WarnLevel, logrusLevel(slog.LevelWarn))
+	assert.Equal(t, log.ErrorLevel, logrusLevel(slog.LevelError))
+	assert.Equal(t, log.ErrorLevel, logrusLevel(slog.LevelError+4))
+}
+
+func TestSlogHandler_errorStackStartsAtCaller(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, slogger := newSlogTestLogger(buf)
+	logger.SetFormatter(&OrderedJSONFormatter{ErrorStacks: true})
+
+	slogger.Error("failed", "error", errors.New("boom"))
+
+	assert.Contains(
+		t,
+		buf.String(),
+		`"stack":["github.com/Admiral-Piett/go-tools/logging.TestSlogHandler_errorStackStartsAtCaller `,
+	)
+}
+
+func TestSlogHandler_concurrentWithLogrus(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, slogger := newSlogTestLogger(buf)
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			slogger.Info("From Slog")
+		}()
+		go func() {
+			defer wg.Done()
+			logger.Info("From Logrus")
+		}()
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 20)
+	for _, line := range lines {
+		assert.True(t, strings.HasPrefix(line, `{"message":"From `), line)
+	}
+}
@@ -0,0 +1,281 @@
+// Package testlog captures log entries in tests so they can be asserted on:
+//
+//	func TestThing(t *testing.T) {
+//	    logs := testlog.Capture(t)
+//
+//	    doThing()
+//
+//	    logs.AssertLogged(t, logrus.WarnLevel, "Thing Failure", logrus.Fields{"userId": "7"})
+//	}
+package testlog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Admiral-Piett/go-tools/logging"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// standard fans the standard logger's entries out to every test capturing it
+var standard = &dispatcher{hooks: map[*Hook]struct{}{}}
+
+// Entry is one captured log entry
+type Entry struct {
+	Level   logrus.Level
+	Message string
+	// Fields includes the fields from the entry's context, as `logging.ContextHook` would add
+	Fields logrus.Fields
+}
+
+// Err returns the error logged with `WithError`, if any
+func (e Entry) Err() error {
+	err, _ := e.Fields[logrus.ErrorKey].(error)
+	return err
+}
+
+// Hook records every entry fired on the logger it's added to
+type Hook struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// Capture records the standard logger's entries (and so slog's default handler's via
+// InitLogging) for the rest of the test.  Output is discarded and every level is recorded while
+// any test is capturing, the logger's output, formatter, level and hooks are put back once the
+// last one finishes.
+//
+// Captures can be nested and run in parallel.  Every capture records entries logged without a
+// context from Hook.Context, so parallel tests should log through their own context, or use
+// NewLogger, before asserting on exactly what was or wasn't logged.
+func Capture(t testing.TB) *Hook {
+	t.Helper()
+	hook := &Hook{}
+	standard.add(hook)
+	t.Cleanup(func() {
+		standard.remove(hook)
+	})
+	return hook
+}
+
+type hookKey struct{}
+
+// Context tags ctx so entries logged with it are only recorded by h, not by other tests
+// capturing at the same time
+func (h *Hook) Context(ctx context.Context) context.Context {
+	return context.WithValue(ctx, hookKey{}, h)
+}
+
+// dispatcher is the one hook on the standard logger while tests are capturing
+type dispatcher struct {
+	mu      sync.Mutex
+	hooks   map[*Hook]struct{}
+	restore func()
+}
+
+func (d *dispatcher) add(hook *Hook) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.hooks) == 0 {
+		logger := logrus.StandardLogger()
+		out, formatter, level, reportCaller := logger.Out, logger.Formatter, logger.GetLevel(), logger.ReportCaller
+		hooks := make(logrus.LevelHooks)
+		hooks.Add(d)
+		previousHooks := logger.ReplaceHooks(hooks)
+		logger.SetOutput(io.Discard)
+		logger.SetLevel(logrus.TraceLevel)
+
+		d.restore = func() {
+			logger.ReplaceHooks(previousHooks)
+			logger.SetOutput(out)
+			logger.SetFormatter(formatter)
+			logger.SetLevel(level)
+			logger.SetReportCaller(reportCaller)
+		}
+	}
+	d.hooks[hook] = struct{}{}
+}
+
+func (d *dispatcher) remove(hook *Hook) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.hooks, hook)
+	if len(d.hooks) == 0 && d.restore != nil {
+		d.restore()
+		d.restore = nil
+	}
+}
+
+// Levels implements logrus.Hook
+func (d *dispatcher) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire implements logrus.Hook, passing entry to the Hook its context was tagged for, or to
+// every Hook if it wasn't
+func (d *dispatcher) Fire(entry *logrus.Entry) error {
+	var owner *Hook
+	if entry.Context != nil {
+		owner, _ = entry.Context.Value(hookKey{}).(*Hook)
+	}
+
+	d.mu.Lock()
+	hooks := make([]*Hook, 0, len(d.hooks))
+	for hook := range d.hooks {
+		if owner == nil || hook == owner {
+			hooks = append(hooks, hook)
+		}
+	}
+	d.mu.Unlock()
+
+	for _, hook := range hooks {
+		hook.Fire(entry)
+	}
+	return nil
+}
+
+// NewLogger returns a logger of its own with a Hook on it, for code that takes a logger.
+// Unlike Capture it only ever sees the test's own entries.
+func NewLogger() (*logrus.Logger, *Hook) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	logger.SetLevel(logrus.TraceLevel)
+	hook := &Hook{}
+	logger.AddHook(hook)
+	return logger, hook
+}
+
+// Levels implements logrus.Hook
+func (h *Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire implements logrus.Hook
+func (h *Hook) Fire(entry *logrus.Entry) error {
+	contextFields := logging.FieldsFromContext(entry.Context)
+	fields := make(logrus.Fields, len(entry.Data)+len(contextFields))
+	for k, v := range contextFields {
+		fields[k] = v
+	}
+	for k, v := range entry.Data {
+		fields[k] = v
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries = append(h.entries, Entry{
+		Level:   entry.Level,
+		Message: entry.Message,
+		Fields:  fields,
+	})
+	return nil
+}
+
+// Entries returns everything captured so far, oldest first
+func (h *Hook) Entries() []Entry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	entries := make([]Entry, len(h.entries))
+	copy(entries, h.entries)
+	return entries
+}
+
+// LastEntry returns the most recent entry, nil if nothing has been logged
+func (h *Hook) LastEntry() *Entry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.entries) == 0 {
+		return nil
+	}
+	entry := h.entries[len(h.entries)-1]
+	return &entry
+}
+
+// Find returns the entries logged at level with message
+func (h *Hook) Find(level logrus.Level, message string) []Entry {
+	var found []Entry
+	for _, entry := range h.Entries() {
+		if entry.Level == level && entry.Message == message {
+			found = append(found, entry)
+		}
+	}
+	return found
+}
+
+// Reset forgets everything captured so far
+func (h *Hook) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries = nil
+}
+
+// AssertLogged checks an entry was logged at level with message, and with every one of fields
+// if given
+func (h *Hook) AssertLogged(
+	t testing.TB,
+	level logrus.Level,
+	message string,
+	fields ...logrus.Fields,
+) bool {
+	t.Helper()
+	candidates := h.Find(level, message)
+	for _, entry := range candidates {
+		if hasFields(entry, fields) {
+			return true
+		}
+	}
+	if len(candidates) > 0 {
+		return assert.Fail(
+			t,
+			fmt.Sprintf("%s %q logged without the expected fields", level, message),
+			"expected %v\nlogged:\n%s", fields, h.describe(candidates),
+		)
+	}
+	return assert.Fail(
+		t,
+		fmt.Sprintf("%s %q not logged", level, message),
+		"logged:\n%s", h.describe(h.Entries()),
+	)
+}
+
+// AssertNotLogged checks nothing was logged at level with message
+func (h *Hook) AssertNotLogged(t testing.TB, level logrus.Level, message string) bool {
+	t.Helper()
+	if found := h.Find(level, message); len(found) > 0 {
+		return assert.Fail(
+			t,
+			fmt.Sprintf("%s %q logged %d times", level, message, len(found)),
+			h.describe(found),
+		)
+	}
+	return true
+}
+
+func hasFields(entry Entry, expected []logrus.Fields) bool {
+	for _, fields := range expected {
+		for k, v := range fields {
+			actual, ok := entry.Fields[k]
+			if !ok || !assert.ObjectsAreEqual(v, actual) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (h *Hook) describe(entries []Entry) string {
+	if len(entries) == 0 {
+		return "  (nothing)"
+	}
+	lines := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		lines = append(lines, fmt.Sprintf("  %s %q %v", entry.Level, entry.Message, entry.Fields))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,133 @@
+package testlog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/Admiral-Piett/go-tools/logging"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCapture_recordsEntries(t *testing.T) {
+	logs := Capture(t)
+	err := errors.New("boom")
+	ctx := logging.WithField(context.Background(), "request_id", "req-1")
+
+	logrus.WithContext(ctx).WithError(err).WithField("user_id", "7").Warn("Lookup Failure")
+	logrus.Debug("Details")
+
+	entries := logs.Entries()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, logrus.WarnLevel, entries[0].Level)
+	assert.Equal(t, "Lookup Failure", entries[0].Message)
+	assert.Equal(t, logrus.Fields{"error": err, "user_id": "7", "request_id": "req-1"}, entries[0].Fields)
+	assert.Equal(t, err, entries[0].Err())
+	assert.Equal(t, "Details", logs.LastEntry().Message)
+}
+
+func TestCapture_restoresStandardLogger(t *testing.T) {
+	logger := logrus.StandardLogger()
+	out, level := logger.Out, logger.GetLevel()
+
+	t.Run("capture", func(t *testing.T) {
+		Capture(t)
+		assert.Equal(t, io.Discard, logger.Out)
+		assert.Equal(t, logrus.TraceLevel, logger.GetLevel())
+	})
+
+	assert.Equal(t, out, logger.Out)
+	assert.Equal(t, level, logger.GetLevel())
+}
+
+func TestCapture_parallel(t *testing.T) {
+	for _, name := range []string{"a", "b", "c"} {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			logs := Capture(t)
+			ctx := logs.Context(context.Background())
+
+			logrus.WithContext(ctx).Info(name)
+
+			assert.Len(t, logs.Find(logrus.InfoLevel, name), 1)
+			for _, other := range []string{"a", "b", "c"} {
+				if other != name {
+					logs.AssertNotLogged(t, logrus.InfoLevel, other)
+				}
+			}
+		})
+	}
+}
+
+func TestCapture_nested(t *testing.T) {
+	logger := logrus.StandardLogger()
+	out := logger.Out
+
+	t.Run("group", func(t *testing.T) {
+		parent := Capture(t)
+		for _, name := range []string{"a", "b"} {
+			name := name
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+				logs := Capture(t)
+
+				logrus.WithContext(logs.Context(context.Background())).Info(name)
+
+				logs.AssertLogged(t, logrus.InfoLevel, name)
+			})
+		}
+		logrus.Info("untagged")
+
+		parent.AssertLogged(t, logrus.InfoLevel, "untagged")
+		parent.AssertNotLogged(t, logrus.InfoLevel, "a")
+	})
+
+	assert.Equal(t, out, logger.Out)
+}
+
+func TestCapture_slog(t *testing.T) {
+	logs := Capture(t)
+	previous := slog.Default()
+	slog.SetDefault(slog.New(logging.NewSlogHandler(logrus.StandardLogger())))
+	defer slog.SetDefault(previous)
+
+	slog.Info("Server Listening", "port", 8080)
+
+	logs.AssertLogged(t, logrus.InfoLevel, "Server Listening", logrus.Fields{"port": int64(8080)})
+}
+
+func TestNewLogger(t *testing.T) {
+	logger, logs := NewLogger()
+
+	logger.WithField("count", 2).Error("Failed")
+
+	logs.AssertLogged(t, logrus.ErrorLevel, "Failed", logrus.Fields{"count": 2})
+	logs.AssertNotLogged(t, logrus.InfoLevel, "Failed")
+	assert.Len(t, logs.Find(logrus.ErrorLevel, "Failed"), 1)
+}
+
+func TestHook_Reset(t *testing.T) {
+	logger, logs := NewLogger()
+	logger.Info("one")
+
+	logs.Reset()
+
+	assert.Empty(t, logs.Entries())
+	assert.Nil(t, logs.LastEntry())
+}
+
+func TestHook_AssertLogged_failures(t *testing.T) {
+	logger, logs := NewLogger()
+	logger.WithField("count", 2).Error("Failed")
+
+	mockT := &testing.T{}
+	assert.False(t, logs.AssertLogged(mockT, logrus.ErrorLevel, "Failed", logrus.Fields{"count": 3}))
+	assert.False(t, logs.AssertLogged(mockT, logrus.ErrorLevel, "Missing"))
+	assert.False(t, logs.AssertNotLogged(mockT, logrus.ErrorLevel, "Failed"))
+	assert.True(t, mockT.Failed())
+}
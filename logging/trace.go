@@ -0,0 +1,73 @@
+package logging
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	CloudTraceContextHeader = "X-Cloud-Trace-Context"
+	TraceparentHeader       = "traceparent"
+
+	// Log fields the trace ids are stored under, the GCP formatter maps them onto Cloud Logging's
+	// trace fields
+	TraceIdField = "trace_id"
+	SpanIdField  = "span_id"
+)
+
+// ParseTraceContext pulls the trace and span ids from a W3C `traceparent` header, falling back
+// to Google's `X-Cloud-Trace-Context` (`TRACE_ID/SPAN_ID;o=1`, with a decimal span id).  Span
+// ids are returned as 16 hex characters either way.  Empty strings mean no usable trace.
+func ParseTraceContext(cloudTraceContext, traceparent string) (traceId, spanId string) {
+	if traceId, spanId, ok := parseTraceparent(traceparent); ok {
+		return traceId, spanId
+	}
+	if traceId, spanId, ok := parseCloudTraceContext(cloudTraceContext); ok {
+		return traceId, spanId
+	}
+	return "", ""
+}
+
+func parseTraceparent(value string) (string, string, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return "", "", false
+	}
+	traceId, spanId := strings.ToLower(parts[1]), strings.ToLower(parts[2])
+	if !isHex(traceId, 32) || !isHex(spanId, 16) ||
+		traceId == strings.Repeat("0", 32) || spanId == strings.Repeat("0", 16) {
+		return "", "", false
+	}
+	return traceId, spanId, true
+}
+
+func parseCloudTraceContext(value string) (string, string, bool) {
+	value = strings.TrimSpace(value)
+	if i := strings.Index(value, ";"); i >= 0 {
+		value = value[:i]
+	}
+	traceId, span, _ := strings.Cut(value, "/")
+	traceId = strings.ToLower(traceId)
+	if !isHex(traceId, 32) {
+		return "", "", false
+	}
+
+	spanId := ""
+	if n, err := strconv.ParseUint(span, 10, 64); err == nil && n != 0 {
+		spanId = fmt.Sprintf("%016x", n)
+	}
+	return traceId, spanId, true
+}
+
+func isHex(value string, length int) bool {
+	if len(value) != length {
+		return false
+	}
+	for _, r := range value {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'f') {
+			return false
+		}
+	}
+	return true
+}
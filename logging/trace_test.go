@@ -0,0 +1,46 @@
+package logging
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTraceContext_traceparent(t *testing.T) {
+	traceId, spanId := ParseTraceContext(
+		"105445aa7843bc8bf206b12000100000/1;o=1",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+	)
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceId)
+	assert.Equal(t, "00f067aa0ba902b7", spanId)
+}
+
+func TestParseTraceContext_cloudTraceContext(t *testing.T) {
+	traceId, spanId := ParseTraceContext("105445aa7843bc8bf206b12000100000/255;o=1", "")
+
+	assert.Equal(t, "105445aa7843bc8bf206b12000100000", traceId)
+	assert.Equal(t, "00000000000000ff", spanId)
+}
+
+func TestParseTraceContext_cloudTraceContextWithoutSpan(t *testing.T) {
+	traceId, spanId := ParseTraceContext("105445aa7843bc8bf206b12000100000", "")
+
+	assert.Equal(t, "105445aa7843bc8bf206b12000100000", traceId)
+	assert.Equal(t, "", spanId)
+}
+
+func TestParseTraceContext_invalid(t *testing.T) {
+	for _, headers := range [][2]string{
+		{"", ""},
+		{"not-a-trace/1", ""},
+		{"", "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+		{"", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{"", "00-4bf92f3577b34da6a3ce929d0e0e4736-01"},
+	} {
+		traceId, spanId := ParseTraceContext(headers[0], headers[1])
+
+		assert.Equal(t, "", traceId, headers)
+		assert.Equal(t, "", spanId, headers)
+	}
+}
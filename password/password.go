@@ -7,6 +7,14 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// TimingHash and TimingSalt are validated against when the account being logged in to doesn't
+// exist, so unknown accounts take as long to reject as wrong passwords and can't be enumerated
+// by response time.
+const (
+	TimingHash = "$2a$10$yBIDtRKQQM4uP0MlYLjHlO4wNvYlJBZ872drjRAzkLzTSobGZZZHK"
+	TimingSalt = "dNSczLZ/bqPL5GHpyx+Y1w=="
+)
+
 func HashPassword(password string) (hash, salt string, err error) {
 	// Generate random salt
 	saltBytes := make([]byte, 16)
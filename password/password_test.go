@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
 )
 
 var (
@@ -49,3 +50,11 @@ func TestValidatePassword_invalidSalt_failure(t *testing.T) {
 
 	assert.False(t, ok)
 }
+
+func TestTimingHash_costsAsMuchAsHashPassword(t *testing.T) {
+	// The dummy compare only evens out response times if it's as expensive as a real one
+	cost, err := bcrypt.Cost([]byte(TimingHash))
+
+	assert.Nil(t, err)
+	assert.Equal(t, bcrypt.DefaultCost, cost)
+}
@@ -44,8 +44,10 @@ type BaseSettings struct {
 	AllowedOrigins string `env:"ALLOWED_ORIGINS" default:"http://localhost:3000"`
 
 	// Logging
-	LogLevel    string `env:"LOG_LEVEL" default:"INFO"`
-	SqlLogLevel string `env:"SQL_LOG_LEVEL" default:"WARNING"`
+	LogLevel           string `env:"LOG_LEVEL" default:"INFO"`
+	LogFormat          string `env:"LOG_FORMAT"` // json, gcp or console, empty picks by environment
+	SqlLogLevel        string `env:"SQL_LOG_LEVEL" default:"WARNING"`
+	GoogleCloudProject string `env:"GOOGLE_CLOUD_PROJECT"` // qualifies trace ids in gcp log format
 
 	SqlType string `env:"SQL_TYPE" default:"sqlite"`
 	SqlUri  string `env:"SQL_URI" default:"path/to/sqlite.db"`
@@ -60,8 +62,23 @@ type BaseSettings struct {
 	JwtAccessTokenTTL  int    `env:"JWT_ACCESS_TOKEN_TTL" default:"5"`
 	JwtRefreshTokenTTL int    `env:"JWT_REFRESH_TOKEN_TTL" default:"10"`
 
+	// Authentication (API Keys)
+	ApiKeyPrefix  string `env:"API_KEY_PREFIX" default:"sk"`
+	ApiKeyHmacKey string `env:"API_KEY_HMAC_KEY"` // hex, separate from JWT_HMAC_KEY so rotating that keeps issued keys valid
+
+	// Service-to-service request signing (HMAC)
+	RequestSigningKey     string `env:"REQUEST_SIGNING_KEY"`
+	RequestSigningWindow  int    `env:"REQUEST_SIGNING_WINDOW" default:"300"`       // seconds
+	RequestSigningMaxBody int64  `env:"REQUEST_SIGNING_MAX_BODY" default:"1048576"` // bytes
+
+	// Client IP resolution behind load balancers/proxies
+	TrustedProxies   string `env:"TRUSTED_PROXIES"`                            // comma separated CIDRs or IPs
+	TrustedProxyHops int    `env:"TRUSTED_PROXY_HOPS" default:"0"`             // proxies in front of the app, e.g. 1 on Cloud Run
+	ClientIpHeader   string `env:"CLIENT_IP_HEADER" default:"X-Forwarded-For"` // e.g. X-Real-IP, True-Client-IP
+
 	// Derived/Post-Processed fields
 	AllowedOriginsSlice []string `json:"-"` // Derived field - populated by PostProcessFields
+	TrustedProxiesSlice []string `json:"-"` // Derived field - populated by PostProcessFields
 }
 
 // PostProcessFields implements the PostProcessSettingsInterface
@@ -81,6 +98,15 @@ func (s *BaseSettings) PostProcessFields() {
 	} else {
 		s.AllowedOriginsSlice = parts
 	}
+
+	// Parse trusted proxies, unlike origins there's no sensible default so empty means none
+	s.TrustedProxiesSlice = []string{}
+	for _, part := range strings.Split(s.TrustedProxies, ",") {
+		trimmed := strings.TrimSpace(part)
+		if trimmed != "" {
+			s.TrustedProxiesSlice = append(s.TrustedProxiesSlice, trimmed)
+		}
+	}
 }
 
 // Load populates any struct with env tags using reflection, then calls PostProcessFields
@@ -369,3 +369,29 @@ func TestBaseSettingsAllFields(t *testing.T) {
 	assert.Equal(t, 15, settings.JwtAccessTokenTTL)
 	assert.Equal(t, 30, settings.JwtRefreshTokenTTL)
 }
+
+func TestPostProcessFieldsTrustedProxies(t *testing.T) {
+	// Setup
+	os.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.168.1.1 ,")
+	defer os.Unsetenv("TRUSTED_PROXIES")
+
+	// Execute
+	settings := &BaseSettings{}
+	err := Load(settings)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.1"}, settings.TrustedProxiesSlice)
+	assert.Equal(t, 0, settings.TrustedProxyHops)
+	assert.Equal(t, "X-Forwarded-For", settings.ClientIpHeader)
+}
+
+func TestPostProcessFieldsWithEmptyTrustedProxies(t *testing.T) {
+	// Execute
+	settings := &BaseSettings{}
+	err := Load(settings)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []string{}, settings.TrustedProxiesSlice)
+}
@@ -0,0 +1,55 @@
+package signing
+
+import (
+	"sync"
+	"time"
+)
+
+// NonceCacheInterface remembers nonces until they expire.  Implement it over a shared store if
+// more than one instance verifies requests, otherwise a nonce could be replayed to another one.
+type NonceCacheInterface interface {
+	// Add records nonce until expiresAt.  It returns false if the nonce is already present.
+	Add(nonce string, expiresAt time.Time) bool
+}
+
+// MemoryNonceCache is a process local NonceCacheInterface
+type MemoryNonceCache struct {
+	mu        sync.Mutex
+	nonces    map[string]time.Time
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+func NewMemoryNonceCache() *MemoryNonceCache {
+	return &MemoryNonceCache{
+		nonces: map[string]time.Time{},
+		now:    time.Now,
+	}
+}
+
+func (m *MemoryNonceCache) Add(nonce string, expiresAt time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.prune(now)
+
+	if existing, ok := m.nonces[nonce]; ok && now.Before(existing) {
+		return false
+	}
+	m.nonces[nonce] = expiresAt
+	return true
+}
+
+// prune drops expired nonces, at most once a second so a busy cache isn't scanned every call
+func (m *MemoryNonceCache) prune(now time.Time) {
+	if now.Sub(m.lastPrune) < time.Second {
+		return
+	}
+	m.lastPrune = now
+	for nonce, expiresAt := range m.nonces {
+		if !now.Before(expiresAt) {
+			delete(m.nonces, nonce)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package signing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryNonceCache_Add_success(t *testing.T) {
+	c := NewMemoryNonceCache()
+
+	assert.True(t, c.Add("nonce", time.Now().Add(time.Minute)))
+	assert.False(t, c.Add("nonce", time.Now().Add(time.Minute)))
+	assert.True(t, c.Add("other-nonce", time.Now().Add(time.Minute)))
+}
+
+func TestMemoryNonceCache_Add_expiredNonceReusable(t *testing.T) {
+	now := time.Now()
+	c := NewMemoryNonceCache()
+	c.now = func() time.Time { return now }
+
+	assert.True(t, c.Add("nonce", now.Add(time.Minute)))
+
+	now = now.Add(2 * time.Minute)
+	assert.True(t, c.Add("nonce", now.Add(time.Minute)))
+}
+
+func TestMemoryNonceCache_prune(t *testing.T) {
+	now := time.Now()
+	c := NewMemoryNonceCache()
+	c.now = func() time.Time { return now }
+	c.Add("old", now.Add(time.Second))
+
+	now = now.Add(time.Minute)
+	c.Add("new", now.Add(time.Minute))
+
+	assert.Len(t, c.nonces, 1)
+	assert.Contains(t, c.nonces, "new")
+}
@@ -0,0 +1,240 @@
+package signing
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Admiral-Piett/go-tools/encryption"
+)
+
+// Signed requests carry these three headers.  The signature is a hex encoded HMAC-SHA256 over
+// the canonical string built by CanonicalString.
+const (
+	SignatureHeader = "X-Signature"
+	TimestampHeader = "X-Signature-Timestamp"
+	NonceHeader     = "X-Signature-Nonce"
+)
+
+var (
+	ErrSignatureMissing = errors.New("request signature headers missing")
+	ErrSignatureInvalid = errors.New("request signature invalid")
+	ErrSignatureExpired = errors.New("request signature outside replay window")
+	ErrNonceReused      = errors.New("request signature nonce already used")
+	ErrBodyTooLarge     = errors.New("signed request body too large")
+)
+
+// DefaultMaxBodyBytes caps the body a Verifier will read, it has to be buffered to be hashed
+// before the signature is known to be good
+const DefaultMaxBodyBytes int64 = 1 << 20
+
+// DecodeKey decodes a hex encoded shared secret, the same format `manual generate` prints for
+// its HMAC secret key.
+func DecodeKey(hexKey string) ([]byte, error) {
+	key, err := hex.DecodeString(hexKey)
+	if err != nil {
+		return nil, fmt.Errorf("signing key must be hex encoded: %w", err)
+	}
+	if len(key) == 0 {
+		return nil, errors.New("signing key missing")
+	}
+	return key, nil
+}
+
+// CanonicalString builds the string that gets signed:
+//
+//	<METHOD>\n<request uri>\n<unix timestamp>\n<nonce>\n<hex sha256 of body>
+func CanonicalString(method, requestURI, timestamp, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	return fmt.Sprintf(
+		"%s\n%s\n%s\n%s\n%s",
+		method,
+		requestURI,
+		timestamp,
+		nonce,
+		hex.EncodeToString(bodyHash[:]),
+	)
+}
+
+// SignRequest adds the signature headers to r.  The body is read and replaced, so r can still
+// be sent afterward.
+func SignRequest(r *http.Request, key []byte, now time.Time) error {
+	body, err := readBody(r, 0)
+	if err != nil {
+		return err
+	}
+
+	nonceBytes := make([]byte, 16)
+	if _, err := rand.Read(nonceBytes); err != nil {
+		return err
+	}
+	nonce := hex.EncodeToString(nonceBytes)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	message := CanonicalString(r.Method, r.URL.RequestURI(), timestamp, nonce, body)
+	r.Header.Set(TimestampHeader, timestamp)
+	r.Header.Set(NonceHeader, nonce)
+	r.Header.Set(SignatureHeader, encryption.SignHMAC(message, key))
+	return nil
+}
+
+// readBody drains the request body and puts an identical reader back in its place.  Bodies
+// over maxBytes fail with ErrBodyTooLarge, 0 reads everything.
+func readBody(r *http.Request, maxBytes int64) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return []byte{}, nil
+	}
+	reader := r.Body
+	if maxBytes > 0 {
+		reader = http.MaxBytesReader(nil, r.Body, maxBytes)
+	}
+	body, err := io.ReadAll(reader)
+	r.Body.Close()
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return nil, ErrBodyTooLarge
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return body, nil
+}
+
+// SigningTransport is an http.RoundTripper that signs every outgoing request.
+//
+// Example:
+//
+//	transport, err := signing.NewSigningTransport(cfg.RequestSigningKey, nil)
+//	client := &http.Client{Transport: transport}
+type SigningTransport struct {
+	key  []byte
+	base http.RoundTripper
+	now  func() time.Time
+}
+
+// NewSigningTransport wraps base, or http.DefaultTransport if base is nil
+func NewSigningTransport(
+	hexKey string,
+	base http.RoundTripper,
+) (*SigningTransport, error) {
+	key, err := DecodeKey(hexKey)
+	if err != nil {
+		return nil, err
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &SigningTransport{
+		key:  key,
+		base: base,
+		now:  time.Now,
+	}, nil
+}
+
+// RoundTrip implements http.RoundTripper.  The signature headers go on a copy, the caller's
+// headers are left alone.  The body is read through GetBody when the request has one (e.g.
+// from http.NewRequest), leaving r.Body as it was, otherwise r.Body is consumed as sending it
+// would be.
+func (t *SigningTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	signed := r.Clone(r.Context())
+	if r.GetBody != nil && r.Body != nil && r.Body != http.NoBody {
+		body, err := r.GetBody()
+		if err != nil {
+			r.Body.Close()
+			return nil, err
+		}
+		signed.Body = body
+		// RoundTrippers always close the request body
+		r.Body.Close()
+	}
+	if err := SignRequest(signed, t.key, t.now()); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(signed)
+}
+
+// Verifier checks incoming signed requests.  A request is only accepted if its signature
+// matches, its timestamp is within the replay window and its nonce hasn't been seen before.
+type Verifier struct {
+	key          []byte
+	window       time.Duration
+	maxBodyBytes int64
+	nonces       NonceCacheInterface
+	now          func() time.Time
+}
+
+// NewVerifier creates a Verifier.  Bodies over maxBodyBytes are rejected with ErrBodyTooLarge
+// before any more is read, 0 uses DefaultMaxBodyBytes.  If nonces is nil an in memory cache is
+// used, which is only safe for a single instance.
+func NewVerifier(
+	hexKey string,
+	window time.Duration,
+	maxBodyBytes int64,
+	nonces NonceCacheInterface,
+) (*Verifier, error) {
+	key, err := DecodeKey(hexKey)
+	if err != nil {
+		return nil, err
+	}
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = DefaultMaxBodyBytes
+	}
+	if nonces == nil {
+		nonces = NewMemoryNonceCache()
+	}
+	return &Verifier{
+		key:          key,
+		window:       window,
+		maxBodyBytes: maxBodyBytes,
+		nonces:       nonces,
+		now:          time.Now,
+	}, nil
+}
+
+// Verify checks r's signature.  The body is read and replaced so handlers can still use it,
+// unless it's over the size limit.
+func (v *Verifier) Verify(r *http.Request) error {
+	signature := r.Header.Get(SignatureHeader)
+	timestamp := r.Header.Get(TimestampHeader)
+	nonce := r.Header.Get(NonceHeader)
+	if signature == "" || timestamp == "" || nonce == "" {
+		return ErrSignatureMissing
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrSignatureInvalid
+	}
+	signedAt := time.Unix(unix, 0)
+	now := v.now()
+	if signedAt.Before(now.Add(-v.window)) || signedAt.After(now.Add(v.window)) {
+		return ErrSignatureExpired
+	}
+
+	body, err := readBody(r, v.maxBodyBytes)
+	if err != nil {
+		return err
+	}
+	message := CanonicalString(r.Method, r.URL.RequestURI(), timestamp, nonce, body)
+	if !encryption.VerifyHMAC(message, signature, v.key) {
+		return ErrSignatureInvalid
+	}
+
+	// Only remember nonces from valid signatures, so garbage requests can't fill the cache.
+	// Nonces have to outlive the whole window on both sides of the signing time.
+	if !v.nonces.Add(nonce, signedAt.Add(v.window)) {
+		return ErrNonceReused
+	}
+	return nil
+}
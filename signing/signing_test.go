@@ -0,0 +1,187 @@
+package signing
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var signingKey = "39ec2ad652a6b32df6664711e13e74f6388f2a67550397e8b97212471e35042e3f5b716d45fc5d65854c48c95c722178c058b69fd2f611ccf6af54ea3db854b8"
+
+func newSignedRequest(t *testing.T, body string) *http.Request {
+	key, _ := DecodeKey(signingKey)
+	r := httptest.NewRequest("POST", "/webhooks/thing?x=1", strings.NewReader(body))
+	if err := SignRequest(r, key, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestDecodeKey_success(t *testing.T) {
+	key, err := DecodeKey(signingKey)
+
+	assert.Nil(t, err)
+	assert.Len(t, key, 64)
+}
+
+func TestDecodeKey_invalid_error(t *testing.T) {
+	_, err := DecodeKey("garbage")
+	assert.Error(t, err)
+
+	_, err = DecodeKey("")
+	assert.Error(t, err)
+}
+
+func TestSignRequest_bodyStillReadable(t *testing.T) {
+	r := newSignedRequest(t, `{"hello":"world"}`)
+
+	body, _ := io.ReadAll(r.Body)
+	assert.Equal(t, `{"hello":"world"}`, string(body))
+	assert.NotEqual(t, "", r.Header.Get(SignatureHeader))
+	assert.NotEqual(t, "", r.Header.Get(TimestampHeader))
+	assert.NotEqual(t, "", r.Header.Get(NonceHeader))
+}
+
+func TestVerifier_Verify_success(t *testing.T) {
+	v, _ := NewVerifier(signingKey, time.Minute, 0, nil)
+	r := newSignedRequest(t, `{"hello":"world"}`)
+
+	err := v.Verify(r)
+
+	assert.Nil(t, err)
+	body, _ := io.ReadAll(r.Body)
+	assert.Equal(t, `{"hello":"world"}`, string(body))
+}
+
+func TestVerifier_Verify_missingHeaders_error(t *testing.T) {
+	v, _ := NewVerifier(signingKey, time.Minute, 0, nil)
+	r := httptest.NewRequest("POST", "/webhooks/thing", nil)
+
+	err := v.Verify(r)
+
+	assert.ErrorIs(t, err, ErrSignatureMissing)
+}
+
+func TestVerifier_Verify_tamperedBody_error(t *testing.T) {
+	v, _ := NewVerifier(signingKey, time.Minute, 0, nil)
+	r := newSignedRequest(t, `{"hello":"world"}`)
+	r.Body = io.NopCloser(bytes.NewBufferString(`{"hello":"mars"}`))
+
+	err := v.Verify(r)
+
+	assert.ErrorIs(t, err, ErrSignatureInvalid)
+}
+
+func TestVerifier_Verify_tamperedPath_error(t *testing.T) {
+	v, _ := NewVerifier(signingKey, time.Minute, 0, nil)
+	r := newSignedRequest(t, "")
+	r.URL.RawQuery = "x=2"
+
+	err := v.Verify(r)
+
+	assert.ErrorIs(t, err, ErrSignatureInvalid)
+}
+
+func TestVerifier_Verify_wrongKey_error(t *testing.T) {
+	v, _ := NewVerifier("abcd", time.Minute, 0, nil)
+	r := newSignedRequest(t, "")
+
+	err := v.Verify(r)
+
+	assert.ErrorIs(t, err, ErrSignatureInvalid)
+}
+
+func TestVerifier_Verify_outsideWindow_error(t *testing.T) {
+	v, _ := NewVerifier(signingKey, time.Minute, 0, nil)
+	r := newSignedRequest(t, "")
+	r.Header.Set(
+		TimestampHeader,
+		strconv.FormatInt(time.Now().Add(-2*time.Minute).Unix(), 10),
+	)
+
+	err := v.Verify(r)
+
+	assert.ErrorIs(t, err, ErrSignatureExpired)
+}
+
+func TestVerifier_Verify_replay_error(t *testing.T) {
+	v, _ := NewVerifier(signingKey, time.Minute, 0, nil)
+	r := newSignedRequest(t, "payload")
+	replay := r.Clone(r.Context())
+	replay.Body = io.NopCloser(bytes.NewBufferString("payload"))
+
+	err := v.Verify(r)
+	assert.Nil(t, err)
+
+	err = v.Verify(replay)
+	assert.ErrorIs(t, err, ErrNonceReused)
+}
+
+func TestVerifier_Verify_bodyTooLarge_error(t *testing.T) {
+	v, _ := NewVerifier(signingKey, time.Minute, 8, nil)
+	r := newSignedRequest(t, `{"hello":"world"}`)
+
+	err := v.Verify(r)
+
+	assert.ErrorIs(t, err, ErrBodyTooLarge)
+}
+
+func TestSigningTransport_RoundTrip_success(t *testing.T) {
+	v, _ := NewVerifier(signingKey, time.Minute, 0, nil)
+	var verifyErr error
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		verifyErr = v.Verify(r)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	transport, err := NewSigningTransport(signingKey, nil)
+	assert.Nil(t, err)
+	client := &http.Client{Transport: transport}
+
+	req, _ := http.NewRequest("POST", server.URL+"/hook?a=b", strings.NewReader("payload"))
+	resp, err := client.Do(req)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Nil(t, verifyErr)
+	// The caller's headers are left alone and the body can be sent again
+	assert.Equal(t, "", req.Header.Get(SignatureHeader))
+	body, _ := req.GetBody()
+	b, _ := io.ReadAll(body)
+	assert.Equal(t, "payload", string(b))
+}
+
+func TestSigningTransport_RoundTrip_noGetBody(t *testing.T) {
+	v, _ := NewVerifier(signingKey, time.Minute, 0, nil)
+	var verifyErr error
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		verifyErr = v.Verify(r)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	transport, _ := NewSigningTransport(signingKey, nil)
+	client := &http.Client{Transport: transport}
+
+	req, _ := http.NewRequest("POST", server.URL+"/hook", io.NopCloser(strings.NewReader("payload")))
+	assert.Nil(t, req.GetBody)
+	resp, err := client.Do(req)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Nil(t, verifyErr)
+}
+
+func TestNewSigningTransport_invalidKey_error(t *testing.T) {
+	_, err := NewSigningTransport("garbage", nil)
+
+	assert.Error(t, err)
+}